@@ -1,70 +1,164 @@
-package main
-
-import (
-	"log"
-	"os"
-	"time"
-
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-	"github.com/joho/godotenv"
-	"github.com/yt-insights/internal/api"
-	"github.com/yt-insights/internal/config"
-	"github.com/yt-insights/internal/models"
-)
-
-func main() {
-	// Load environment variables from .env file
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: .env file not found")
-	}
-
-	// Load configuration
-	cfg, err := config.Load()
-	if err != nil {
-		log.Fatalf("Failed to load configuration: %v", err)
-	}
-
-	// Initialize database
-	db, err := models.NewDatabase(cfg.DBPath)
-	if err != nil {
-		log.Fatalf("Failed to initialize database: %v", err)
-	}
-
-	// Initialize YouTube API
-	youtubeAPI, err := api.NewYouTubeAPI(cfg.YouTubeAPIKey, db)
-	if err != nil {
-		log.Fatalf("Failed to initialize YouTube API: %v", err)
-	}
-
-	// Initialize router
-	router := gin.Default()
-
-	// Configure CORS
-	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:3002", "https://ytca-frontend.vercel.app"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Pragma"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
-
-	// Register routes
-	router.GET("/channel/url", youtubeAPI.GetChannelByURL)
-	router.GET("/channel/:id", youtubeAPI.GetChannelByID)
-	router.GET("/channel/:id/videos", youtubeAPI.GetChannelVideos)
-	router.GET("/channel/:id/analytics", youtubeAPI.GetChannelAnalytics)
-	router.GET("/channel/:id/trends", youtubeAPI.GetChannelTrends)
-
-	// Start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
-
-	log.Printf("Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-}
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+	// Embed the time zone database so ?tz= works on hosts without one
+	_ "time/tzdata"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"github.com/yt-insights/internal/alerts"
+	"github.com/yt-insights/internal/api"
+	"github.com/yt-insights/internal/config"
+	"github.com/yt-insights/internal/models"
+	"github.com/yt-insights/internal/retention"
+	"github.com/yt-insights/internal/scheduler"
+	"github.com/yt-insights/internal/websub"
+)
+
+func main() {
+	// Load environment variables from .env file
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Warning: .env file not found")
+	}
+
+	// Load configuration
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// Initialize database
+	db, err := models.NewDatabase(cfg.DBPath)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	db.SetPayloadCompression(cfg.CompressPayloads)
+
+	// Start the background compactor for stored analytics payloads
+	policy := retention.NewPolicy(cfg.RetentionDailyDays, cfg.RetentionWeeklyDays, cfg.RetentionMonthlyDays)
+	compactor := retention.NewCompactor(db, policy, cfg.CompressPayloads, cfg.CompactionInterval)
+	go compactor.Start(context.Background())
+	storageHandler := api.NewStorageHandler(compactor)
+
+	// Initialize YouTube API
+	youtubeAPI, err := api.NewYouTubeAPI(cfg, db)
+	if err != nil {
+		log.Fatalf("Failed to initialize YouTube API: %v", err)
+	}
+
+	// Start the background refresh of watchlisted channels
+	location, err := time.LoadLocation(cfg.SchedulerTimezone)
+	if err != nil {
+		log.Fatalf("Invalid scheduler time zone: %v", err)
+	}
+	schedule, err := scheduler.ParseSchedule(cfg.RefreshSchedule, location)
+	if err != nil {
+		log.Fatalf("Invalid refresh schedule: %v", err)
+	}
+	refreshScheduler := scheduler.New(db, youtubeAPI, schedule, cfg.RefreshSpread, int64(cfg.SchedulerDailyQuota))
+	if cfg.SchedulerEnabled {
+		go refreshScheduler.Start(context.Background())
+	} else {
+		log.Printf("Background refresh disabled")
+	}
+	jobsHandler := api.NewJobsHandler(refreshScheduler, db)
+
+	// Pick up analytics jobs interrupted by the last shutdown
+	youtubeAPI.ResumeAnalyticsJobs()
+
+	// Keep watchlisted channels subscribed to the WebSub hub for new-upload notifications
+	subscriber := websub.NewSubscriber(db, cfg.WebSubHubURL, cfg.WebSubCallbackURL, cfg.WebSubSecret, cfg.WebSubLease, cfg.WebSubRenewInterval)
+	if cfg.WebSubEnabled {
+		if cfg.WebSubSecret == "" {
+			log.Printf("WEBSUB_SECRET is not set, so notifications cannot be authenticated")
+		}
+		go subscriber.Start(context.Background())
+	} else {
+		log.Printf("WebSub notifications disabled")
+	}
+	websubHandler := api.NewWebSubHandler(subscriber, youtubeAPI, db)
+
+	// Evaluate alert rules and deliver webhooks in the background
+	dispatcher := alerts.NewDispatcher(db, cfg.WebhookTimeout, cfg.WebhookMaxAttempts, cfg.WebhookRetryBackoff)
+	alertEngine := alerts.NewEngine(db, dispatcher, cfg.AlertsInterval)
+	go alertEngine.Start(context.Background())
+	alertsHandler := api.NewAlertsHandler(db, alertEngine)
+
+	// Initialize router
+	router := gin.Default()
+
+	// Configure CORS
+	router.Use(cors.New(cors.Config{
+		AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://localhost:3002", "https://ytca-frontend.vercel.app"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "HEAD"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Requested-With", "Pragma", "Cache-Control", "If-None-Match", "If-Modified-Since"},
+		ExposeHeaders:    []string{"Content-Length", "Content-Type", "Cache-Control", "ETag", "Last-Modified"},
+		AllowCredentials: true,
+		MaxAge:           12 * time.Hour,
+	}))
+
+	// Register routes
+	router.GET("/channel/url", youtubeAPI.GetChannelByURL)
+	router.GET("/channel/:id", youtubeAPI.GetChannelByID)
+	router.GET("/channel/:id/videos", youtubeAPI.GetChannelVideos)
+	router.GET("/channel/:id/analytics", youtubeAPI.GetChannelAnalytics)
+	router.GET("/channel/:id/trends", youtubeAPI.GetChannelTrends)
+	router.GET("/channel/:id/analytics/history", youtubeAPI.GetChannelAnalyticsHistory)
+	router.POST("/channel/:id/analytics/jobs", youtubeAPI.CreateAnalyticsJob)
+	router.GET("/channel/:id/analytics/stream", youtubeAPI.StreamChannelAnalytics)
+	router.GET("/channel/:id/trends/history", youtubeAPI.GetChannelTrendsHistory)
+	router.GET("/channel/:id/outliers", youtubeAPI.GetChannelOutliers)
+	router.GET("/channel/:id/publishing", youtubeAPI.GetChannelPublishing)
+	router.GET("/channel/:id/cadence", youtubeAPI.GetChannelCadence)
+	router.GET("/channel/:id/forecast", youtubeAPI.GetChannelForecast)
+	router.GET("/channel/:id/performance", youtubeAPI.GetChannelPerformance)
+	router.GET("/channel/:id/titles", youtubeAPI.GetChannelTitles)
+	router.GET("/channel/:id/tags", youtubeAPI.GetChannelTags)
+	router.GET("/compare", youtubeAPI.CompareChannels)
+	router.GET("/storage/compaction", storageHandler.GetCompactionStats)
+	router.POST("/storage/compaction", storageHandler.RunCompaction)
+	router.GET("/cache/stats", youtubeAPI.GetCacheStats)
+	router.POST("/watchlist", youtubeAPI.AddToWatchlist)
+	router.GET("/watchlist", youtubeAPI.GetWatchlist)
+	router.GET("/watchlist/tags", youtubeAPI.CompareWatchlistTags)
+	router.GET("/watchlist/:id", youtubeAPI.GetWatchlistEntry)
+	router.DELETE("/watchlist/:id", youtubeAPI.RemoveFromWatchlist)
+	router.GET("/jobs", jobsHandler.GetJobs)
+	router.POST("/jobs/refresh", jobsHandler.RunRefresh)
+	router.GET("/jobs/:id", jobsHandler.GetJob)
+	router.GET("/websub/subscriptions", websubHandler.GetSubscriptions)
+	router.POST("/websub/subscriptions/sync", websubHandler.SyncSubscriptions)
+	router.POST("/alerts", alertsHandler.CreateAlert)
+	router.GET("/alerts", alertsHandler.GetAlerts)
+	router.GET("/alerts/:id", alertsHandler.GetAlert)
+	router.PUT("/alerts/:id", alertsHandler.UpdateAlert)
+	router.DELETE("/alerts/:id", alertsHandler.DeleteAlert)
+	router.POST("/alerts/:id/test", alertsHandler.TestAlert)
+	router.GET("/alerts/:id/deliveries", alertsHandler.GetAlertDeliveries)
+
+	// Only accept hub callbacks while subscriptions are kept; unsigned pushes could
+	// otherwise add or remove stored videos
+	if cfg.WebSubEnabled {
+		router.GET("/websub/callback", websubHandler.VerifySubscription)
+		router.POST("/websub/callback", websubHandler.ReceiveNotification)
+	}
+
+	// Start server
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+
+	log.Printf("Server starting on port %s", port)
+	if err := router.Run(":" + port); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
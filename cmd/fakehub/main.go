@@ -0,0 +1,300 @@
+// Command fakehub is a minimal local WebSub hub for exercising the API's push receiver.
+//
+// Point the API at it with WEBSUB_HUB_URL=http://localhost:9090/subscribe and
+// WEBSUB_CALLBACK_URL=http://localhost:8080/websub/callback, then publish a sample
+// upload with:
+//
+//	curl -X POST "http://localhost:9090/publish?channel_id=UC...&video_id=..."
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/yt-insights/internal/websub"
+)
+
+// subscription is one verified callback registered for a topic
+type subscription struct {
+	Callback     string    `json:"callback"`
+	Topic        string    `json:"topic"`
+	LeaseSeconds int64     `json:"leaseSeconds"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	secret       string
+}
+
+// delivery is the outcome of pushing a notification to one subscriber
+type delivery struct {
+	Callback string `json:"callback"`
+	Status   int    `json:"status,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// hub keeps verified subscriptions in memory
+type hub struct {
+	mu            sync.Mutex
+	subscriptions map[string]*subscription
+	client        *http.Client
+}
+
+var feedTemplate = template.Must(template.New("feed").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <link rel="hub" href="https://pubsubhubbub.appspot.com"/>
+  <link rel="self" href="{{.Topic}}"/>
+  <title>YouTube video feed</title>
+  <updated>{{.Now}}</updated>
+  <entry>
+    <id>yt:video:{{.VideoID}}</id>
+    <yt:videoId>{{.VideoID}}</yt:videoId>
+    <yt:channelId>{{.ChannelID}}</yt:channelId>
+    <title>{{.Title}}</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v={{.VideoID}}"/>
+    <author>
+      <name>Fake Hub</name>
+      <uri>https://www.youtube.com/channel/{{.ChannelID}}</uri>
+    </author>
+    <published>{{.Now}}</published>
+    <updated>{{.Now}}</updated>
+  </entry>
+</feed>
+`))
+
+var deletedTemplate = template.Must(template.New("deleted").Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:at="http://purl.org/atompub/tombstones/1.0" xmlns="http://www.w3.org/2005/Atom">
+  <at:deleted-entry ref="yt:video:{{.VideoID}}" when="{{.Now}}">
+    <link href="https://www.youtube.com/watch?v={{.VideoID}}"/>
+    <at:by>
+      <name>Fake Hub</name>
+      <uri>https://www.youtube.com/channel/{{.ChannelID}}</uri>
+    </at:by>
+  </at:deleted-entry>
+</feed>
+`))
+
+func main() {
+	port := os.Getenv("FAKEHUB_PORT")
+	if port == "" {
+		port = "9090"
+	}
+
+	h := &hub{
+		subscriptions: make(map[string]*subscription),
+		client:        &http.Client{Timeout: 10 * time.Second},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/subscribe", h.handleSubscribe)
+	mux.HandleFunc("/publish", h.handlePublish(feedTemplate))
+	mux.HandleFunc("/delete", h.handlePublish(deletedTemplate))
+	mux.HandleFunc("/subscriptions", h.handleSubscriptions)
+
+	log.Printf("Fake WebSub hub listening on port %s", port)
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
+		log.Fatalf("Failed to start fake hub: %v", err)
+	}
+}
+
+// handleSubscribe accepts a subscription request and verifies it with the subscriber asynchronously
+func (h *hub) handleSubscribe(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mode := r.PostForm.Get("hub.mode")
+	callback := r.PostForm.Get("hub.callback")
+	topic := r.PostForm.Get("hub.topic")
+	if (mode != "subscribe" && mode != "unsubscribe") || callback == "" || topic == "" {
+		http.Error(w, "hub.mode, hub.callback and hub.topic are required", http.StatusBadRequest)
+		return
+	}
+
+	var lease int64 = 432000
+	if value := r.PostForm.Get("hub.lease_seconds"); value != "" {
+		fmt.Sscan(value, &lease)
+	}
+
+	sub := &subscription{
+		Callback:     callback,
+		Topic:        topic,
+		LeaseSeconds: lease,
+		secret:       r.PostForm.Get("hub.secret"),
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	go h.verify(mode, sub)
+}
+
+// verify performs the intent verification round trip and records the outcome
+func (h *hub) verify(mode string, sub *subscription) {
+	challenge := randomHex(16)
+
+	query := url.Values{
+		"hub.mode":      {mode},
+		"hub.topic":     {sub.Topic},
+		"hub.challenge": {challenge},
+	}
+	if mode == "subscribe" {
+		query.Set("hub.lease_seconds", fmt.Sprint(sub.LeaseSeconds))
+	}
+
+	verifyURL, err := url.Parse(sub.Callback)
+	if err != nil {
+		log.Printf("Invalid callback %s: %v", sub.Callback, err)
+		return
+	}
+	existing := verifyURL.Query()
+	for key, values := range query {
+		existing[key] = values
+	}
+	verifyURL.RawQuery = existing.Encode()
+
+	resp, err := h.client.Get(verifyURL.String())
+	if err != nil {
+		log.Printf("Verification of %s failed: %v", sub.Callback, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if resp.StatusCode != http.StatusOK || string(body) != challenge {
+		log.Printf("Subscriber %s did not confirm %s of %s (%s)", sub.Callback, mode, sub.Topic, resp.Status)
+		return
+	}
+
+	key := sub.Callback + " " + sub.Topic
+	h.mu.Lock()
+	if mode == "subscribe" {
+		sub.ExpiresAt = time.Now().UTC().Add(time.Duration(sub.LeaseSeconds) * time.Second)
+		h.subscriptions[key] = sub
+	} else {
+		delete(h.subscriptions, key)
+	}
+	h.mu.Unlock()
+
+	log.Printf("Verified %s of %s for %s", mode, sub.Topic, sub.Callback)
+}
+
+// handlePublish pushes a sample notification for ?channel_id= and ?video_id= to every
+// subscriber of the channel's topic
+func (h *hub) handlePublish(tmpl *template.Template) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		channelID := r.URL.Query().Get("channel_id")
+		videoID := r.URL.Query().Get("video_id")
+		if channelID == "" || videoID == "" {
+			http.Error(w, "channel_id and video_id are required", http.StatusBadRequest)
+			return
+		}
+		title := r.URL.Query().Get("title")
+		if title == "" {
+			title = "Sample upload " + videoID
+		}
+
+		topic := websub.TopicURL(channelID)
+		var buf bytes.Buffer
+		err := tmpl.Execute(&buf, map[string]string{
+			"Topic":     xmlEscape(topic),
+			"ChannelID": xmlEscape(channelID),
+			"VideoID":   xmlEscape(videoID),
+			"Title":     xmlEscape(title),
+			"Now":       time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		deliveries := []delivery{}
+		for _, sub := range h.subscribers(topic) {
+			deliveries = append(deliveries, h.deliver(sub, buf.Bytes()))
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"topic": topic, "deliveries": deliveries})
+	}
+}
+
+// deliver posts a notification to one subscriber, signing it if the subscriber gave a secret
+func (h *hub) deliver(sub *subscription, body []byte) delivery {
+	req, err := http.NewRequest(http.MethodPost, sub.Callback, bytes.NewReader(body))
+	if err != nil {
+		return delivery{Callback: sub.Callback, Error: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/atom+xml")
+	req.Header.Set("Link", fmt.Sprintf(`<%s>; rel="self"`, sub.Topic))
+	if sub.secret != "" {
+		req.Header.Set("X-Hub-Signature", websub.Sign(sub.secret, body))
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return delivery{Callback: sub.Callback, Error: err.Error()}
+	}
+	resp.Body.Close()
+
+	log.Printf("Delivered notification for %s to %s: %s", sub.Topic, sub.Callback, resp.Status)
+	return delivery{Callback: sub.Callback, Status: resp.StatusCode}
+}
+
+// subscribers returns the unexpired subscriptions to a topic
+func (h *hub) subscribers(topic string) []*subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var subs []*subscription
+	for _, sub := range h.subscriptions {
+		if sub.Topic == topic && time.Now().Before(sub.ExpiresAt) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// handleSubscriptions lists the verified subscriptions
+func (h *hub) handleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	subs := make([]*subscription, 0, len(h.subscriptions))
+	for _, sub := range h.subscriptions {
+		subs = append(subs, sub)
+	}
+	h.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+// xmlEscape makes a query value safe to place in the Atom templates
+func xmlEscape(value string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(value))
+	return buf.String()
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprint(time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,162 @@
+package alerts
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// dueDeliveriesBatch bounds how many retries are attempted per pass
+const dueDeliveriesBatch = 100
+
+// Sign returns the X-Webhook-Signature value for a payload sent at the given unix timestamp.
+// Receivers recompute HMAC-SHA256 over "<timestamp>.<body>" with the rule's secret.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSecret returns a random signing secret for a new rule
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Dispatcher delivers events to webhooks, logging every delivery and retrying failures
+// with exponential backoff
+type Dispatcher struct {
+	db           *models.Database
+	client       *http.Client
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+// NewDispatcher creates a dispatcher that gives up on a delivery after maxAttempts tries
+func NewDispatcher(db *models.Database, timeout time.Duration, maxAttempts int, retryBackoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		client:       &http.Client{Timeout: timeout},
+		maxAttempts:  maxAttempts,
+		retryBackoff: retryBackoff,
+	}
+}
+
+// Send logs a delivery of the event and makes the first attempt
+func (d *Dispatcher) Send(rule *models.AlertRule, event Event) (*models.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode event: %v", err)
+	}
+
+	delivery := &models.WebhookDelivery{
+		RuleID:    rule.ID,
+		EventType: string(event.Type),
+		Payload:   payload,
+	}
+	if err := d.db.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	d.attempt(rule, delivery)
+	return d.db.GetDelivery(delivery.ID)
+}
+
+// RetryDue re-attempts pending deliveries whose backoff has elapsed
+func (d *Dispatcher) RetryDue() error {
+	deliveries, err := d.db.GetDueDeliveries(dueDeliveriesBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		rule, err := d.db.GetAlertRule(delivery.RuleID)
+		if err != nil {
+			log.Printf("Failed to load alert rule %d for retry: %v", delivery.RuleID, err)
+			continue
+		}
+		if rule == nil {
+			if err := d.db.RecordDeliveryAttempt(delivery.ID, models.DeliveryStatusFailed, 0, "alert rule was deleted", time.Time{}); err != nil {
+				log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+			}
+			continue
+		}
+		d.attempt(rule, delivery)
+	}
+	return nil
+}
+
+// attempt posts the delivery once and records the outcome
+func (d *Dispatcher) attempt(rule *models.AlertRule, delivery *models.WebhookDelivery) {
+	responseStatus, err := d.post(rule, delivery)
+
+	status, errMsg, nextAttemptAt := models.DeliveryStatusDelivered, "", time.Time{}
+	if err != nil {
+		errMsg = err.Error()
+		attempts := delivery.Attempts + 1
+		if attempts >= d.maxAttempts {
+			status = models.DeliveryStatusFailed
+			log.Printf("Webhook delivery %d for rule %d failed after %d attempts: %v", delivery.ID, rule.ID, attempts, err)
+		} else {
+			status = models.DeliveryStatusPending
+			nextAttemptAt = time.Now().Add(retryDelay(d.retryBackoff, attempts))
+			log.Printf("Webhook delivery %d for rule %d failed, retrying at %s: %v", delivery.ID, rule.ID, nextAttemptAt.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := d.db.RecordDeliveryAttempt(delivery.ID, status, responseStatus, errMsg, nextAttemptAt); err != nil {
+		log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// retryDelay is how long to wait after a delivery's attempts-th failure; the wait
+// doubles with every failed attempt
+func retryDelay(backoff time.Duration, attempts int) time.Duration {
+	return backoff << uint(attempts-1)
+}
+
+// post sends the payload with its signature headers; any non-2xx response is an error
+func (d *Dispatcher) post(rule *models.AlertRule, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, rule.WebhookURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %v", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "yt-insights-webhooks/1.0")
+	req.Header.Set("X-Webhook-Event", delivery.EventType)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(timestamp, 10))
+	if rule.Secret != "" {
+		req.Header.Set("X-Webhook-Signature", Sign(rule.Secret, timestamp, delivery.Payload))
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
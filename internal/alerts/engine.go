@@ -0,0 +1,171 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// Engine periodically evaluates the enabled alert rules against the stored channel data.
+// It makes no API calls of its own: data arrives through the scheduler, WebSub
+// notifications and regular requests.
+type Engine struct {
+	db         *models.Database
+	dispatcher *Dispatcher
+	interval   time.Duration
+}
+
+// NewEngine creates an engine that evaluates rules on every interval
+func NewEngine(db *models.Database, dispatcher *Dispatcher, interval time.Duration) *Engine {
+	return &Engine{db: db, dispatcher: dispatcher, interval: interval}
+}
+
+// Start evaluates rules and retries due deliveries on every interval until ctx is done.
+// A zero interval disables alerting.
+func (e *Engine) Start(ctx context.Context) {
+	if e.interval <= 0 {
+		log.Printf("Alert evaluation disabled")
+		return
+	}
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.RunOnce(); err != nil {
+			log.Printf("Alert evaluation failed: %v", err)
+		}
+		if err := e.dispatcher.RetryDue(); err != nil {
+			log.Printf("Webhook retries failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce evaluates every enabled rule and sends the events they produce
+func (e *Engine) RunOnce() error {
+	rules, err := e.db.GetAlertRules("", true)
+	if err != nil {
+		return err
+	}
+
+	// Rules on the same channel share one read of its data
+	type channelData struct {
+		channel *models.Channel
+		videos  []models.Video
+	}
+	loaded := make(map[string]*channelData)
+
+	now := time.Now().UTC()
+	for _, rule := range rules {
+		data, ok := loaded[rule.ChannelID]
+		if !ok {
+			channel, err := e.db.GetChannel(rule.ChannelID)
+			if err != nil {
+				log.Printf("Failed to load channel %s for alerts: %v", rule.ChannelID, err)
+				continue
+			}
+			var videos []models.Video
+			if channel != nil {
+				if videos, err = e.db.GetVideosByChannel(rule.ChannelID); err != nil {
+					log.Printf("Failed to load videos of channel %s for alerts: %v", rule.ChannelID, err)
+					continue
+				}
+			}
+			data = &channelData{channel: channel, videos: videos}
+			loaded[rule.ChannelID] = data
+		}
+		// Nothing is known about the channel until its uploads have been crawled once.
+		// A stored row alone may predate the crawl, and a rule initialized against it
+		// would report the whole back catalogue as new uploads.
+		if data.channel == nil || data.channel.VideosSyncedAt.IsZero() {
+			continue
+		}
+
+		if err := e.evaluateRule(rule, data.channel, data.videos, now); err != nil {
+			log.Printf("Failed to evaluate alert rule %d: %v", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// evaluateRule runs one rule, stores its new state and delivers its events
+func (e *Engine) evaluateRule(rule *models.AlertRule, channel *models.Channel, videos []models.Video, now time.Time) error {
+	var state ruleState
+	if rule.State != "" {
+		if err := json.Unmarshal([]byte(rule.State), &state); err != nil {
+			log.Printf("Resetting unreadable state of alert rule %d: %v", rule.ID, err)
+			state = ruleState{}
+		}
+	}
+
+	events, state := evaluate(rule, state, channel, videos, now)
+
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert state: %v", err)
+	}
+	// Save before sending so a crash can't make the same event fire twice
+	if err := e.db.SaveAlertState(rule.ID, string(stateJSON), len(events) > 0); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		log.Printf("Alert rule %d (%s) fired for channel %s", rule.ID, rule.Type, rule.ChannelID)
+		if _, err := e.dispatcher.Send(rule, event); err != nil {
+			log.Printf("Failed to send alert for rule %d: %v", rule.ID, err)
+		}
+	}
+	return nil
+}
+
+// TestFire sends a sample event for a rule, built from the stored channel data where possible,
+// and returns the logged delivery
+func (e *Engine) TestFire(rule *models.AlertRule) (*models.WebhookDelivery, error) {
+	channel, err := e.db.GetChannel(rule.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel == nil {
+		channel = &models.Channel{ID: rule.ChannelID}
+	}
+
+	var latest models.Video
+	if videos, err := e.db.GetVideosByChannel(rule.ChannelID); err == nil && len(videos) > 0 {
+		latest = videos[0]
+	}
+
+	event := Event{
+		Type:         rule.Type,
+		RuleID:       rule.ID,
+		ChannelID:    rule.ChannelID,
+		ChannelTitle: channel.Title,
+		OccurredAt:   time.Now().UTC(),
+		Test:         true,
+	}
+	switch rule.Type {
+	case models.AlertTypeNewUpload:
+		event.Data = NewUploadData{Video: latest}
+	case models.AlertTypeViral:
+		event.Data = ViralData{Video: latest, MedianViews: float64(latest.Views) / rule.Threshold, Multiple: rule.Threshold}
+	case models.AlertTypeSubscriberMilestone:
+		step := int64(rule.Threshold)
+		if step <= 0 {
+			step = defaultMilestoneStep
+		}
+		event.Data = MilestoneData{Milestone: (channel.Subscribers/step + 1) * step, SubscriberCount: channel.Subscribers}
+	case models.AlertTypeLikeRatioDrop:
+		event.Data = LikeRatioDropData{RecentRatio: 0.02 * (1 - rule.Threshold), BaselineRatio: 0.02, DropPercent: rule.Threshold * 100}
+	}
+
+	return e.dispatcher.Send(rule, event)
+}
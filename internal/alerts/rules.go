@@ -0,0 +1,245 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	defaultViralMultiple      = 3.0
+	defaultViralWindowHours   = 24
+	defaultMilestoneStep      = 100000
+	defaultLikeRatioDrop      = 0.3
+	likeRatioRecentVideos     = 10
+	likeRatioBaselineVideos   = 50
+	maxRememberedViralVideos  = 200
+	minViralComparisonVideos  = 5
+	minLikeRatioBaselineViews = 1000
+)
+
+// Event is something a rule detected, delivered as the webhook payload
+type Event struct {
+	Type         models.AlertType `json:"type"`
+	RuleID       int64            `json:"ruleId"`
+	ChannelID    string           `json:"channelId"`
+	ChannelTitle string           `json:"channelTitle"`
+	OccurredAt   time.Time        `json:"occurredAt"`
+	Test         bool             `json:"test,omitempty"`
+	Data         interface{}      `json:"data"`
+}
+
+// NewUploadData describes a new_upload event
+type NewUploadData struct {
+	Video models.Video `json:"video"`
+}
+
+// ViralData describes a viral event
+type ViralData struct {
+	Video       models.Video `json:"video"`
+	MedianViews float64      `json:"medianViews"`
+	Multiple    float64      `json:"multiple"`
+}
+
+// MilestoneData describes a subscriber_milestone event
+type MilestoneData struct {
+	Milestone       int64 `json:"milestone"`
+	SubscriberCount int64 `json:"subscriberCount"`
+}
+
+// LikeRatioDropData describes a like_ratio_drop event
+type LikeRatioDropData struct {
+	RecentRatio   float64 `json:"recentRatio"`
+	BaselineRatio float64 `json:"baselineRatio"`
+	DropPercent   float64 `json:"dropPercent"`
+}
+
+// ruleState is what a rule remembers between evaluations so each event fires once.
+// A rule's first evaluation only records the current state.
+type ruleState struct {
+	Initialized     bool      `json:"initialized"`
+	LastPublishedAt time.Time `json:"lastPublishedAt,omitempty"`
+	NotifiedVideos  []string  `json:"notifiedVideos,omitempty"`
+	LastMilestone   int64     `json:"lastMilestone,omitempty"`
+	Dropped         bool      `json:"dropped,omitempty"`
+}
+
+// ApplyDefaults fills in a rule's threshold and window when they were left at zero
+func ApplyDefaults(rule *models.AlertRule) {
+	switch rule.Type {
+	case models.AlertTypeViral:
+		if rule.Threshold <= 0 {
+			rule.Threshold = defaultViralMultiple
+		}
+		if rule.WindowHours <= 0 {
+			rule.WindowHours = defaultViralWindowHours
+		}
+	case models.AlertTypeSubscriberMilestone:
+		if rule.Threshold <= 0 {
+			rule.Threshold = defaultMilestoneStep
+		}
+	case models.AlertTypeLikeRatioDrop:
+		if rule.Threshold <= 0 {
+			rule.Threshold = defaultLikeRatioDrop
+		}
+	}
+}
+
+// Validate checks that a rule's type and parameters make sense
+func Validate(rule *models.AlertRule) error {
+	switch rule.Type {
+	case models.AlertTypeNewUpload, models.AlertTypeViral, models.AlertTypeSubscriberMilestone:
+	case models.AlertTypeLikeRatioDrop:
+		if rule.Threshold >= 1 {
+			return fmt.Errorf("like_ratio_drop threshold is a fraction between 0 and 1")
+		}
+	default:
+		return fmt.Errorf("unknown alert type %q", rule.Type)
+	}
+	if rule.Threshold < 0 || rule.WindowHours < 0 {
+		return fmt.Errorf("threshold and windowHours must not be negative")
+	}
+	return nil
+}
+
+// evaluate checks a rule against a channel and its videos (newest first), returning the
+// events to deliver and the state to remember
+func evaluate(rule *models.AlertRule, state ruleState, channel *models.Channel, videos []models.Video, now time.Time) ([]Event, ruleState) {
+	var events []Event
+	newEvent := func(data interface{}) Event {
+		return Event{
+			Type:         rule.Type,
+			RuleID:       rule.ID,
+			ChannelID:    rule.ChannelID,
+			ChannelTitle: channel.Title,
+			OccurredAt:   now,
+			Data:         data,
+		}
+	}
+
+	switch rule.Type {
+	case models.AlertTypeNewUpload:
+		latest := state.LastPublishedAt
+		for _, v := range videos {
+			if v.PublishedAt.After(state.LastPublishedAt) {
+				if state.Initialized {
+					events = append(events, newEvent(NewUploadData{Video: v}))
+				}
+				if v.PublishedAt.After(latest) {
+					latest = v.PublishedAt
+				}
+			}
+		}
+		state.LastPublishedAt = latest
+
+	case models.AlertTypeViral:
+		window := time.Duration(rule.WindowHours) * time.Hour
+		notified := make(map[string]bool, len(state.NotifiedVideos))
+		for _, id := range state.NotifiedVideos {
+			notified[id] = true
+		}
+		for _, v := range videos {
+			if notified[v.ID] || now.Sub(v.PublishedAt) > window || now.Before(v.PublishedAt) {
+				continue
+			}
+			median, ok := medianViewsExcluding(videos, v.ID)
+			if !ok || median <= 0 {
+				continue
+			}
+			multiple := float64(v.Views) / median
+			if multiple >= rule.Threshold {
+				events = append(events, newEvent(ViralData{Video: v, MedianViews: median, Multiple: multiple}))
+				state.NotifiedVideos = append(state.NotifiedVideos, v.ID)
+			}
+		}
+		if len(state.NotifiedVideos) > maxRememberedViralVideos {
+			state.NotifiedVideos = state.NotifiedVideos[len(state.NotifiedVideos)-maxRememberedViralVideos:]
+		}
+
+	case models.AlertTypeSubscriberMilestone:
+		step := int64(rule.Threshold)
+		if step <= 0 {
+			break
+		}
+		milestone := channel.Subscribers / step * step
+		if state.Initialized && milestone > state.LastMilestone {
+			events = append(events, newEvent(MilestoneData{Milestone: milestone, SubscriberCount: channel.Subscribers}))
+		}
+		// Dips below a milestone aren't reported, and recrossing it later doesn't fire again
+		if milestone > state.LastMilestone {
+			state.LastMilestone = milestone
+		}
+
+	case models.AlertTypeLikeRatioDrop:
+		recent, baseline, ok := likeRatios(videos)
+		if !ok {
+			break
+		}
+		dropped := recent < baseline*(1-rule.Threshold)
+		if dropped && !state.Dropped && state.Initialized {
+			events = append(events, newEvent(LikeRatioDropData{
+				RecentRatio:   recent,
+				BaselineRatio: baseline,
+				DropPercent:   (1 - recent/baseline) * 100,
+			}))
+		}
+		// Fire once per drop; the rule re-arms when the ratio recovers
+		state.Dropped = dropped
+	}
+
+	state.Initialized = true
+	return events, state
+}
+
+// medianViewsExcluding returns the median views of every video but one
+func medianViewsExcluding(videos []models.Video, excludeID string) (float64, bool) {
+	views := make([]float64, 0, len(videos))
+	for _, v := range videos {
+		if v.ID != excludeID {
+			views = append(views, float64(v.Views))
+		}
+	}
+	if len(views) < minViralComparisonVideos {
+		return 0, false
+	}
+	sort.Float64s(views)
+	mid := len(views) / 2
+	if len(views)%2 == 0 {
+		return (views[mid-1] + views[mid]) / 2, true
+	}
+	return views[mid], true
+}
+
+// likeRatios returns the like-to-view ratio of the most recent uploads and of the
+// uploads before them
+func likeRatios(videos []models.Video) (float64, float64, bool) {
+	if len(videos) < likeRatioRecentVideos*2 {
+		return 0, 0, false
+	}
+
+	end := likeRatioRecentVideos + likeRatioBaselineVideos
+	if end > len(videos) {
+		end = len(videos)
+	}
+	recent := likeRatio(videos[:likeRatioRecentVideos])
+	baseline := likeRatio(videos[likeRatioRecentVideos:end])
+	if math.IsNaN(recent) || math.IsNaN(baseline) || baseline == 0 {
+		return 0, 0, false
+	}
+	return recent, baseline, true
+}
+
+func likeRatio(videos []models.Video) float64 {
+	var views, likes int64
+	for _, v := range videos {
+		views += v.Views
+		likes += v.Likes
+	}
+	if views < minLikeRatioBaselineViews {
+		return math.NaN()
+	}
+	return float64(likes) / float64(views)
+}
@@ -0,0 +1,388 @@
+package alerts
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// evalStart is the time of the first evaluation in every scenario
+var evalStart = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// video returns a fixture published hoursAgo hours before evalStart
+func video(id string, hoursAgo float64, views, likes int64) models.Video {
+	return models.Video{
+		ID:          id,
+		PublishedAt: evalStart.Add(-time.Duration(hoursAgo * float64(time.Hour))),
+		Views:       views,
+		Likes:       likes,
+	}
+}
+
+// evalStep is one evaluation of a rule and the events it should produce, named by eventKey
+type evalStep struct {
+	subscribers int64
+	videos      []models.Video
+	want        []string
+}
+
+// eventKey names an event by the video or milestone it is about
+func eventKey(e Event) string {
+	switch data := e.Data.(type) {
+	case NewUploadData:
+		return data.Video.ID
+	case ViralData:
+		return data.Video.ID
+	case MilestoneData:
+		return fmt.Sprint(data.Milestone)
+	case LikeRatioDropData:
+		return "drop"
+	}
+	return fmt.Sprintf("%T", e.Data)
+}
+
+// runSteps evaluates the rule once per step an hour apart, carrying its state over
+func runSteps(t *testing.T, rule *models.AlertRule, steps []evalStep) {
+	t.Helper()
+	var state ruleState
+	for i, step := range steps {
+		channel := &models.Channel{ID: rule.ChannelID, Subscribers: step.subscribers}
+		var events []Event
+		events, state = evaluate(rule, state, channel, step.videos, evalStart.Add(time.Duration(i)*time.Hour))
+
+		got := make([]string, 0, len(events))
+		for _, e := range events {
+			if e.Type != rule.Type || e.RuleID != rule.ID || e.ChannelID != rule.ChannelID {
+				t.Errorf("step %d: event %+v does not belong to rule %d", i, e, rule.ID)
+			}
+			got = append(got, eventKey(e))
+		}
+		want := step.want
+		if want == nil {
+			want = []string{}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("step %d: events = %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestEvaluateNewUpload(t *testing.T) {
+	a, b := video("a", 48, 100, 0), video("b", 24, 100, 0)
+	c, d, e := video("c", 2, 10, 0), video("d", 1, 5, 0), video("e", 0.5, 1, 0)
+	late := video("late", 72, 100, 0)
+
+	tests := []struct {
+		name  string
+		steps []evalStep
+	}{
+		{
+			name: "first run is silent",
+			steps: []evalStep{
+				{videos: []models.Video{b, a}},
+				{videos: []models.Video{b, a}},
+			},
+		},
+		{
+			name: "fires once per upload",
+			steps: []evalStep{
+				{videos: []models.Video{b, a}},
+				{videos: []models.Video{c, b, a}, want: []string{"c"}},
+				{videos: []models.Video{c, b, a}},
+				{videos: []models.Video{e, d, c, b, a}, want: []string{"e", "d"}},
+				{videos: []models.Video{e, d, c, b, a}},
+			},
+		},
+		{
+			name: "videos older than the newest seen are not new",
+			steps: []evalStep{
+				{videos: []models.Video{b, a}},
+				{videos: []models.Video{b, a, late}},
+			},
+		},
+		{
+			name: "channel without uploads",
+			steps: []evalStep{
+				{},
+				{videos: []models.Video{a}, want: []string{"a"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSteps(t, &models.AlertRule{ID: 1, ChannelID: "UC1", Type: models.AlertTypeNewUpload}, tt.steps)
+		})
+	}
+}
+
+func TestEvaluateViral(t *testing.T) {
+	// Five older uploads with a median of 1000 views
+	catalogue := []models.Video{
+		video("o1", 500, 800, 0), video("o2", 600, 900, 0), video("o3", 700, 1000, 0),
+		video("o4", 800, 1100, 0), video("o5", 900, 1200, 0),
+	}
+	with := func(videos ...models.Video) []models.Video {
+		return append(videos, catalogue...)
+	}
+
+	tests := []struct {
+		name        string
+		threshold   float64
+		windowHours int
+		steps       []evalStep
+	}{
+		{
+			name: "fires when the multiple is reached, once",
+			steps: []evalStep{
+				{videos: with(video("v", 2, 2500, 0))},
+				{videos: with(video("v", 2, 3000, 0)), want: []string{"v"}},
+				{videos: with(video("v", 2, 9000, 0))},
+			},
+		},
+		{
+			name:      "higher threshold",
+			threshold: 5,
+			steps: []evalStep{
+				{videos: with(video("v", 2, 4000, 0))},
+				{videos: with(video("v", 2, 5000, 0)), want: []string{"v"}},
+			},
+		},
+		{
+			name: "only uploads inside the window",
+			steps: []evalStep{
+				{videos: with(video("old", 30, 50000, 0), video("new", 20, 50000, 0)), want: []string{"new"}},
+			},
+		},
+		{
+			name:        "wider window",
+			windowHours: 48,
+			steps: []evalStep{
+				{videos: with(video("old", 30, 50000, 0)), want: []string{"old"}},
+			},
+		},
+		{
+			name: "scheduled uploads are ignored",
+			steps: []evalStep{
+				{videos: with(video("premiere", -5, 50000, 0))},
+			},
+		},
+		{
+			name: "too few uploads to compare with",
+			steps: []evalStep{
+				{videos: append([]models.Video{video("v", 2, 50000, 0)}, catalogue[:4]...)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &models.AlertRule{ID: 2, ChannelID: "UC1", Type: models.AlertTypeViral, Threshold: tt.threshold, WindowHours: tt.windowHours}
+			ApplyDefaults(rule)
+			runSteps(t, rule, tt.steps)
+		})
+	}
+}
+
+func TestEvaluateSubscriberMilestone(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []evalStep
+	}{
+		{
+			name: "first run is silent",
+			steps: []evalStep{
+				{subscribers: 2500},
+				{subscribers: 2999},
+			},
+		},
+		{
+			name: "fires when crossing",
+			steps: []evalStep{
+				{subscribers: 1500},
+				{subscribers: 1999},
+				{subscribers: 2000, want: []string{"2000"}},
+				{subscribers: 2400},
+			},
+		},
+		{
+			name: "a dip and recross does not fire again",
+			steps: []evalStep{
+				{subscribers: 1500},
+				{subscribers: 2100, want: []string{"2000"}},
+				{subscribers: 1900},
+				{subscribers: 2050},
+				{subscribers: 3000, want: []string{"3000"}},
+			},
+		},
+		{
+			name: "several milestones at once fire as the highest",
+			steps: []evalStep{
+				{subscribers: 1500},
+				{subscribers: 4200, want: []string{"4000"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runSteps(t, &models.AlertRule{ID: 3, ChannelID: "UC1", Type: models.AlertTypeSubscriberMilestone, Threshold: 1000}, tt.steps)
+		})
+	}
+}
+
+func TestEvaluateLikeRatioDrop(t *testing.T) {
+	// Ten recent uploads with recentLikes likes per 1000 views before twenty with 50
+	uploads := func(recentLikes int64) []models.Video {
+		videos := make([]models.Video, 0, 30)
+		for i := 0; i < 30; i++ {
+			likes := int64(50)
+			if i < likeRatioRecentVideos {
+				likes = recentLikes
+			}
+			videos = append(videos, video(fmt.Sprintf("v%02d", i), float64(24*(i+1)), 1000, likes))
+		}
+		return videos
+	}
+
+	tests := []struct {
+		name  string
+		steps []evalStep
+	}{
+		{
+			name: "fires once, then re-arms on recovery",
+			steps: []evalStep{
+				{videos: uploads(50)},
+				{videos: uploads(30), want: []string{"drop"}},
+				{videos: uploads(20)},
+				{videos: uploads(45)},
+				{videos: uploads(20), want: []string{"drop"}},
+			},
+		},
+		{
+			name: "a drop under the threshold does not fire",
+			steps: []evalStep{
+				{videos: uploads(50)},
+				{videos: uploads(36)},
+			},
+		},
+		{
+			name: "a drop present on the first run waits for a recovery",
+			steps: []evalStep{
+				{videos: uploads(20)},
+				{videos: uploads(20)},
+				{videos: uploads(50)},
+				{videos: uploads(20), want: []string{"drop"}},
+			},
+		},
+		{
+			name: "too few uploads",
+			steps: []evalStep{
+				{videos: uploads(50)[:19]},
+				{videos: uploads(10)[:19]},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := &models.AlertRule{ID: 4, ChannelID: "UC1", Type: models.AlertTypeLikeRatioDrop}
+			ApplyDefaults(rule)
+			runSteps(t, rule, tt.steps)
+		})
+	}
+}
+
+func TestMedianViewsExcluding(t *testing.T) {
+	views := func(counts ...int64) []models.Video {
+		videos := make([]models.Video, len(counts))
+		for i, n := range counts {
+			videos[i] = video(fmt.Sprintf("v%d", i), 24, n, 0)
+		}
+		return videos
+	}
+
+	tests := []struct {
+		name    string
+		videos  []models.Video
+		exclude string
+		want    float64
+		wantOK  bool
+	}{
+		{"odd count", views(5, 1, 3, 2, 4), "none", 3, true},
+		{"even count", views(1, 2, 3, 4, 5, 100), "none", 3.5, true},
+		{"excluded video is left out", views(1, 2, 3, 4, 5, 1000), "v5", 3, true},
+		{"too few", views(1, 2, 3, 4, 5), "v0", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := medianViewsExcluding(tt.videos, tt.exclude)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("medianViewsExcluding = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLikeRatios(t *testing.T) {
+	uploads := func(n int, views, recentLikes, olderLikes int64) []models.Video {
+		videos := make([]models.Video, n)
+		for i := range videos {
+			likes := olderLikes
+			if i < likeRatioRecentVideos {
+				likes = recentLikes
+			}
+			videos[i] = video(fmt.Sprintf("v%d", i), float64(i), views, likes)
+		}
+		return videos
+	}
+
+	tests := []struct {
+		name                   string
+		videos                 []models.Video
+		wantRecent, wantBefore float64
+		wantOK                 bool
+	}{
+		{"ratios", uploads(20, 1000, 20, 50), 0.02, 0.05, true},
+		{"baseline capped", append(uploads(60, 1000, 20, 50), uploads(20, 1000, 0, 0)...), 0.02, 0.05, true},
+		{"too few uploads", uploads(19, 1000, 20, 50), 0, 0, false},
+		{"too few views", uploads(20, 50, 1, 1), 0, 0, false},
+		{"no likes before", uploads(20, 1000, 20, 0), 0, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recent, baseline, ok := likeRatios(tt.videos)
+			if recent != tt.wantRecent || baseline != tt.wantBefore || ok != tt.wantOK {
+				t.Errorf("likeRatios = %v, %v, %v, want %v, %v, %v", recent, baseline, ok, tt.wantRecent, tt.wantBefore, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("whsec_test", 1700000000, []byte(`{"type":"new_upload"}`))
+	want := "sha256=4bb8c4947228618a28487278aa4fce470704aa3d0cdfa6fe54a322ecd06de807"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if other := Sign("whsec_test", 1700000001, []byte(`{"type":"new_upload"}`)); other == got || !strings.HasPrefix(other, "sha256=") {
+		t.Errorf("signature does not cover the timestamp: %s", other)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{6, 32 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(time.Minute, tt.attempts); got != tt.want {
+			t.Errorf("retryDelay after %d attempts = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
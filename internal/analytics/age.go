@@ -0,0 +1,202 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// DefaultEarlyDays is the N in "views in the first N days"
+	DefaultEarlyDays = 7
+	// defaultAgeExponent is used when too few snapshots exist to fit the curve;
+	// views growing with the square root of age is typical of a back catalogue
+	defaultAgeExponent = 0.4
+	// minCurveVideos is the fewest videos with snapshots the exponent is fitted from
+	minCurveVideos = 5
+	// snapshotTimeOfDay places a snapshot, recorded some time on its date, at midday
+	snapshotTimeOfDay = 12 * time.Hour
+)
+
+// AgeDays returns how many days a video had been published at t, at least one
+func AgeDays(v models.Video, t time.Time) float64 {
+	return math.Max(t.Sub(v.PublishedAt).Hours()/24, 1)
+}
+
+// ViewsPerDay returns a video's views divided by its age in days
+func ViewsPerDay(v models.Video, now time.Time) float64 {
+	return float64(v.Views) / AgeDays(v, now)
+}
+
+// FitAgeCurve fits the channel's expected views by age. The exponent is the median
+// growth rate between each video's first and last snapshot, or a default with
+// too few snapshots; the scale is then the median of views / age^exponent.
+func FitAgeCurve(videos []models.Video, snapshots []models.VideoSnapshot, earlyDays int, now time.Time) models.AgeCurve {
+	curve := models.AgeCurve{EarlyDays: earlyDays, Exponent: defaultAgeExponent}
+
+	published := make(map[string]models.Video, len(videos))
+	for _, v := range videos {
+		published[v.ID] = v
+	}
+
+	var rates []float64
+	for videoID, series := range snapshotsByVideo(snapshots) {
+		v, ok := published[videoID]
+		if !ok || len(series) < 2 {
+			continue
+		}
+		first, last := series[0], series[len(series)-1]
+		a1, a2 := snapshotAge(v, first), snapshotAge(v, last)
+		// Snapshots too close in age say little about the growth rate
+		if first.Views <= 0 || last.Views < first.Views || a2 < 1.2*a1 {
+			continue
+		}
+		rates = append(rates, math.Log(float64(last.Views)/float64(first.Views))/math.Log(a2/a1))
+	}
+	if len(rates) >= minCurveVideos {
+		curve.Exponent = math.Min(math.Max(Median(rates), 0.05), 1)
+		curve.FromSnapshots = true
+	}
+
+	var scales []float64
+	for _, v := range videos {
+		if v.Views > 0 {
+			scales = append(scales, float64(v.Views)/math.Pow(AgeDays(v, now), curve.Exponent))
+		}
+	}
+	curve.Scale = Median(scales)
+	return curve
+}
+
+// ExpectedViews returns the views the curve expects of a video ageDays old
+func ExpectedViews(curve models.AgeCurve, ageDays float64) float64 {
+	return curve.Scale * math.Pow(ageDays, curve.Exponent)
+}
+
+// AgePerformance normalizes each video's views by its age, returned in the order given.
+// Views in the first EarlyDays days are interpolated from the video's snapshots when they
+// span that age, and otherwise scaled from its current views along the curve.
+func AgePerformance(videos []models.Video, snapshots []models.VideoSnapshot, curve models.AgeCurve, now time.Time) []models.VideoAgePerformance {
+	byVideo := snapshotsByVideo(snapshots)
+
+	performance := make([]models.VideoAgePerformance, 0, len(videos))
+	for _, v := range videos {
+		age := AgeDays(v, now)
+		expected := ExpectedViews(curve, age)
+		early, measured := earlyViews(v, byVideo[v.ID], float64(curve.EarlyDays))
+		if !measured {
+			early = float64(v.Views) * math.Pow(float64(curve.EarlyDays)/age, curve.Exponent)
+		}
+
+		p := models.VideoAgePerformance{
+			VideoID:             v.ID,
+			Title:               v.Title,
+			PublishedAt:         v.PublishedAt,
+			AgeDays:             age,
+			Views:               v.Views,
+			ViewsPerDay:         float64(v.Views) / age,
+			EarlyViews:          early,
+			EarlyViewsEstimated: !measured,
+			ExpectedViews:       expected,
+		}
+		if expected > 0 {
+			p.PerformanceVsExpected = float64(v.Views) / expected
+		}
+		performance = append(performance, p)
+	}
+	return performance
+}
+
+// ComputeAgePerformance lists every video of a channel by its performance for its age
+func ComputeAgePerformance(channel *models.Channel, videos []models.Video, snapshots []models.VideoSnapshot, earlyDays int, now time.Time) *models.ChannelAgePerformance {
+	curve := FitAgeCurve(videos, snapshots, earlyDays, now)
+	return &models.ChannelAgePerformance{
+		ChannelID:    channel.ID,
+		ChannelTitle: channel.Title,
+		Curve:        curve,
+		Videos:       RankByAge(AgePerformance(videos, snapshots, curve, now)),
+		Timestamp:    now,
+	}
+}
+
+// RankByAge orders age-normalized performance by PerformanceVsExpected, highest first
+func RankByAge(performance []models.VideoAgePerformance) []models.VideoAgePerformance {
+	ranked := make([]models.VideoAgePerformance, len(performance))
+	copy(ranked, performance)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].PerformanceVsExpected != ranked[j].PerformanceVsExpected {
+			return ranked[i].PerformanceVsExpected > ranked[j].PerformanceVsExpected
+		}
+		return ranked[i].VideoID < ranked[j].VideoID
+	})
+	return ranked
+}
+
+// AgeNormalizedTrends summarizes age-normalized performance per period.
+// Periods without uploads are included with zero values.
+func AgeNormalizedTrends(videos []models.Video, performance []models.VideoAgePerformance, g Granularity, loc *time.Location) []models.AgeNormalizedTrend {
+	byID := make(map[string]models.VideoAgePerformance, len(performance))
+	for _, p := range performance {
+		byID[p.VideoID] = p
+	}
+
+	periods, groups := periodGroups(videos, g, loc)
+	trends := make([]models.AgeNormalizedTrend, 0, len(periods))
+	for _, period := range periods {
+		trend := models.AgeNormalizedTrend{
+			Period:      period.Key,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+		}
+		if group := groups[period.Key]; len(group) > 0 {
+			var perDay, vsExpected []float64
+			var early float64
+			for _, v := range group {
+				p := byID[v.ID]
+				perDay = append(perDay, p.ViewsPerDay)
+				vsExpected = append(vsExpected, p.PerformanceVsExpected)
+				early += p.EarlyViews
+			}
+			trend.Uploads = len(group)
+			trend.MedianViewsPerDay = Median(perDay)
+			trend.AverageEarlyViews = early / float64(len(group))
+			trend.MedianPerformanceVsExpected = Median(vsExpected)
+		}
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// snapshotsByVideo groups snapshots by video, each group oldest first
+func snapshotsByVideo(snapshots []models.VideoSnapshot) map[string][]models.VideoSnapshot {
+	byVideo := make(map[string][]models.VideoSnapshot)
+	for _, s := range snapshots {
+		byVideo[s.VideoID] = append(byVideo[s.VideoID], s)
+	}
+	for _, series := range byVideo {
+		sort.Slice(series, func(i, j int) bool { return series[i].SnapshotDate.Before(series[j].SnapshotDate) })
+	}
+	return byVideo
+}
+
+// snapshotAge returns the video's age in days when the snapshot was recorded
+func snapshotAge(v models.Video, s models.VideoSnapshot) float64 {
+	return AgeDays(v, s.SnapshotDate.Add(snapshotTimeOfDay))
+}
+
+// earlyViews interpolates a video's views at the given age from snapshots either side of it
+func earlyViews(v models.Video, series []models.VideoSnapshot, days float64) (float64, bool) {
+	for i := 1; i < len(series); i++ {
+		a1, a2 := snapshotAge(v, series[i-1]), snapshotAge(v, series[i])
+		if a1 <= days && days <= a2 {
+			t := 0.0
+			if a2 > a1 {
+				t = (days - a1) / (a2 - a1)
+			}
+			return float64(series[i-1].Views) + t*float64(series[i].Views-series[i-1].Views), true
+		}
+	}
+	return 0, false
+}
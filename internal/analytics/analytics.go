@@ -0,0 +1,178 @@
+// Package analytics computes channel analytics and trends from a channel's videos.
+//
+// Every function here is pure: it works only on the values passed in and never
+// modifies them, so the HTTP handlers can feed it videos from the API, the
+// database or the cache and always get the same result for the same input.
+//
+// Conventions shared by all results:
+//   - Videos are ranked by a Scorer, highest first, ties broken by views and
+//     then by ID. DefaultScorer matches Video.EngagementScore.
+//   - Per-video series (performance over time, rolling averages) run in
+//     chronological order, oldest upload first.
+//   - Rolling averages use a trailing window of the current upload and the
+//     RollingWindow-1 uploads before it.
+//   - Age-normalized performance compares a video's views with the channel's
+//     age curve, the views it expects of a video that many days old.
+//   - Outliers compare a video with the median views of the uploads published
+//     nearest to it, not with the channel as a whole, so a channel's growth
+//     does not make every recent upload look like a hit.
+//   - Time series bucket uploads by calendar period (see Granularity) of their
+//     publish time in a requested time zone, UTC by default. Every series runs
+//     in ascending period order from the first upload's period to the last,
+//     with empty periods included so gaps show up as zeros.
+package analytics
+
+import (
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// TopVideosCount is how many videos the top engaging and trending lists hold
+	TopVideosCount = 5
+	// RollingWindow is the number of uploads averaged by the rolling average
+	RollingWindow = 10
+)
+
+// Totals returns the summed views, likes and comments of the videos
+func Totals(videos []models.Video) (views, likes, comments int64) {
+	for _, v := range videos {
+		views += v.Views
+		likes += v.Likes
+		comments += v.Comments
+	}
+	return views, likes, comments
+}
+
+// scoreContext builds the scoring context for a channel
+func scoreContext(channel *models.Channel, videos []models.Video, snapshots []models.VideoSnapshot, now time.Time) ScoreContext {
+	return ScoreContext{
+		Subscribers: channel.Subscribers,
+		Now:         now,
+		Curve:       FitAgeCurve(videos, snapshots, DefaultEarlyDays, now),
+	}
+}
+
+// ComputeAnalytics summarizes a channel's engagement across the given videos,
+// ranking the top videos with scorer. snapshots may be nil.
+func ComputeAnalytics(channel *models.Channel, videos []models.Video, snapshots []models.VideoSnapshot, now time.Time, scorer Scorer) *models.ChannelAnalytics {
+	totalViews, totalLikes, totalComments := Totals(videos)
+
+	var averageViews, likeToViewRatio, commentToViewRatio float64
+	if len(videos) > 0 {
+		averageViews = float64(totalViews) / float64(len(videos))
+	}
+	if totalViews > 0 {
+		likeToViewRatio = float64(totalLikes) / float64(totalViews)
+		commentToViewRatio = float64(totalComments) / float64(totalViews)
+	}
+
+	return &models.ChannelAnalytics{
+		ChannelID:          channel.ID,
+		ChannelTitle:       channel.Title,
+		ChannelName:        channel.Title,
+		SubscriberCount:    channel.Subscribers,
+		ViewCount:          channel.ViewCount,
+		VideoCount:         channel.VideoCount,
+		TotalVideos:        len(videos),
+		AverageViews:       averageViews,
+		LikeToViewRatio:    likeToViewRatio,
+		CommentToViewRatio: commentToViewRatio,
+		TopEngagingVideos:  Top(videos, TopVideosCount, scorer, scoreContext(channel, videos, snapshots, now)),
+		TimeRange:          PublishedRange(videos),
+		DurationBuckets:    DurationBuckets(videos),
+		Scoring:            scoringModel(scorer),
+		Timestamp:          now,
+	}
+}
+
+// TrendOptions tunes ComputeTrends; zero values select the defaults
+type TrendOptions struct {
+	// Scorer picks the trending videos, DefaultScorer when nil
+	Scorer Scorer
+	// Granularity of the UploadFrequency and EngagementTrends series, DefaultGranularity when empty
+	Granularity Granularity
+	// Location whose calendar the series are bucketed by, UTC when nil
+	Location *time.Location
+}
+
+// withDefaults fills in the zero-valued options
+func (o TrendOptions) withDefaults() TrendOptions {
+	if o.Scorer == nil {
+		o.Scorer = DefaultScorer
+	}
+	if o.Granularity == "" {
+		o.Granularity = DefaultGranularity
+	}
+	if o.Location == nil {
+		o.Location = time.UTC
+	}
+	return o
+}
+
+// ComputeTrends builds the time series views of a channel's videos.
+// snapshots may be nil; they sharpen the age curve.
+func ComputeTrends(channel *models.Channel, videos []models.Video, snapshots []models.VideoSnapshot, now time.Time, opts TrendOptions) *models.ChannelTrends {
+	opts = opts.withDefaults()
+	loc := opts.Location
+
+	chronological := Chronological(videos)
+	ctx := scoreContext(channel, videos, snapshots, now)
+	performance := AgePerformance(chronological, snapshots, ctx.Curve, now)
+
+	ageNormalized := RankByAge(performance)
+	if len(ageNormalized) > TopVideosCount {
+		ageNormalized = ageNormalized[:TopVideosCount]
+	}
+
+	return &models.ChannelTrends{
+		ChannelID:               channel.ID,
+		ChannelTitle:            channel.Title,
+		ChannelName:             channel.Title,
+		TrendingVideos:          Top(videos, TopVideosCount, opts.Scorer, ctx),
+		PerformanceOverTime:     PerformanceOverTime(chronological),
+		RollingAverages:         RollingAverages(chronological, RollingWindow),
+		UploadFrequencyWeekly:   UploadFrequency(chronological, GranularityWeek, loc),
+		UploadFrequencyMonthly:  UploadFrequency(chronological, GranularityMonth, loc),
+		EngagementTrendsWeekly:  EngagementTrends(chronological, GranularityWeek, loc),
+		EngagementTrendsMonthly: EngagementTrends(chronological, GranularityMonth, loc),
+		Granularity:             string(opts.Granularity),
+		Timezone:                loc.String(),
+		UploadFrequency:         UploadFrequency(chronological, opts.Granularity, loc),
+		EngagementTrends:        EngagementTrends(chronological, opts.Granularity, loc),
+		AgeCurve:                &ctx.Curve,
+		AgeNormalizedVideos:     ageNormalized,
+		AgeNormalizedWeekly:     AgeNormalizedTrends(chronological, performance, GranularityWeek, loc),
+		AgeNormalizedMonthly:    AgeNormalizedTrends(chronological, performance, GranularityMonth, loc),
+		Scoring:                 scoringModel(opts.Scorer),
+		Timestamp:               now,
+	}
+}
+
+// scoringModel describes the scorer for echoing in a response
+func scoringModel(scorer Scorer) *models.ScoringModel {
+	model := scorer.Model()
+	return &model
+}
+
+// PublishedRange returns the dates of the oldest and newest uploads
+func PublishedRange(videos []models.Video) models.TimeRange {
+	if len(videos) == 0 {
+		return models.TimeRange{}
+	}
+
+	oldest, newest := videos[0].PublishedAt, videos[0].PublishedAt
+	for _, v := range videos[1:] {
+		if v.PublishedAt.Before(oldest) {
+			oldest = v.PublishedAt
+		}
+		if v.PublishedAt.After(newest) {
+			newest = v.PublishedAt
+		}
+	}
+	return models.TimeRange{
+		StartDate: oldest.Format("2006-01-02"),
+		EndDate:   newest.Format("2006-01-02"),
+	}
+}
@@ -0,0 +1,195 @@
+package analytics
+
+import (
+	"math"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// minScheduleSegment is the fewest gaps on either side of a schedule change
+	minScheduleSegment = 5
+	// scheduleChangeRatio is how far apart the median gaps either side of a change must be
+	scheduleChangeRatio = 1.5
+	// maxScheduleDepth bounds how many times the upload history is split
+	maxScheduleDepth = 4
+
+	oneDay  = 24 * time.Hour
+	oneWeek = 7 * oneDay
+)
+
+// ComputeCadence measures the gaps between a channel's uploads, its weekly upload
+// streaks and when its schedule changed.
+//
+// The consistency score is 100 / (1 + CV), where CV is the coefficient of
+// variation of the gaps: evenly spaced uploads score 100 and the score falls
+// towards 0 as gaps become more irregular.
+//
+// Schedule changes are found by binary segmentation of the log gaps: the history
+// is split where that best separates short gaps from long ones, provided both
+// sides have at least five gaps and their medians differ by half again, and
+// each side is split again in turn.
+func ComputeCadence(channel *models.Channel, videos []models.Video, now time.Time) *models.ChannelCadence {
+	cadence := &models.ChannelCadence{
+		ChannelID:       channel.ID,
+		ChannelTitle:    channel.Title,
+		TotalVideos:     len(videos),
+		ScheduleChanges: []models.ScheduleChange{},
+		Timestamp:       now,
+	}
+	if len(videos) == 0 {
+		return cadence
+	}
+
+	ordered := Chronological(videos)
+	first, last := ordered[0].PublishedAt, ordered[len(ordered)-1].PublishedAt
+	cadence.FirstUpload, cadence.LastUpload = &first, &last
+	cadence.CurrentStreak, cadence.LongestStreak = weeklyStreaks(ordered, now)
+
+	gaps := uploadGaps(ordered)
+	if len(gaps) == 0 {
+		return cadence
+	}
+
+	days := make([]float64, len(gaps))
+	var sum float64
+	longest := gaps[0]
+	for i, g := range gaps {
+		days[i] = g.Days
+		sum += g.Days
+		if g.Days > longest.Days {
+			longest = g
+		}
+	}
+	mean := sum / float64(len(days))
+	cadence.MeanGapDays = mean
+	cadence.MedianGapDays = Median(days)
+	cadence.LongestHiatus = &longest
+
+	if mean > 0 {
+		var squares float64
+		for _, d := range days {
+			squares += (d - mean) * (d - mean)
+		}
+		cv := math.Sqrt(squares/float64(len(days))) / mean
+		cadence.ConsistencyScore = 100 / (1 + cv)
+	}
+
+	splits := scheduleSplits(days, 0, len(days), 0)
+	start := 0
+	for _, split := range splits {
+		cadence.ScheduleChanges = append(cadence.ScheduleChanges, models.ScheduleChange{
+			Date:                gaps[split].From,
+			MedianGapDaysBefore: Median(days[start:split]),
+			MedianGapDaysAfter:  Median(days[split:nextSplit(splits, split, len(days))]),
+		})
+		start = split
+	}
+
+	// Expect the next upload on the schedule the channel has kept since its last change
+	recent := Median(days[start:])
+	next := last.Add(time.Duration(recent * float64(oneDay)))
+	cadence.NextExpectedUpload = &next
+	cadence.Overdue = now.After(next)
+
+	return cadence
+}
+
+// uploadGaps returns the gaps between consecutive uploads, oldest first
+func uploadGaps(ordered []models.Video) []models.UploadGap {
+	gaps := make([]models.UploadGap, 0, len(ordered))
+	for i := 1; i < len(ordered); i++ {
+		from, to := ordered[i-1].PublishedAt, ordered[i].PublishedAt
+		gaps = append(gaps, models.UploadGap{From: from, To: to, Days: to.Sub(from).Hours() / 24})
+	}
+	return gaps
+}
+
+// weeklyStreaks returns the current and longest runs of ISO weeks with an upload
+func weeklyStreaks(ordered []models.Video, now time.Time) (current, longest models.UploadStreak) {
+	groups := groupByPeriod(ordered, GranularityWeek, time.UTC)
+
+	var run models.UploadStreak
+	thisWeek := PeriodOf(now, GranularityWeek, time.UTC).Key
+	for _, week := range PeriodRange(ordered[0].PublishedAt, now, GranularityWeek, time.UTC) {
+		key := week.Key
+		if len(groups[key]) > 0 {
+			if run.Weeks == 0 {
+				run.StartWeek = key
+			}
+			run.Weeks++
+			run.EndWeek = key
+			if run.Weeks > longest.Weeks {
+				longest = run
+			}
+		} else if key != thisWeek {
+			run = models.UploadStreak{}
+		}
+	}
+
+	// A streak still counts as current while this week has no upload yet
+	lastWeek := PeriodOf(now.Add(-oneWeek), GranularityWeek, time.UTC).Key
+	if run.EndWeek == thisWeek || run.EndWeek == lastWeek {
+		current = run
+	}
+	return current, longest
+}
+
+// scheduleSplits returns, in ascending order, the gap indexes in [lo, hi) at
+// which the schedule changed
+func scheduleSplits(days []float64, lo, hi, depth int) []int {
+	if depth >= maxScheduleDepth || hi-lo < 2*minScheduleSegment {
+		return nil
+	}
+
+	logs := make([]float64, hi-lo)
+	for i := range logs {
+		// Gaps under an hour count as an hour so same-day uploads stay finite
+		logs[i] = math.Log(math.Max(days[lo+i], 1.0/24))
+	}
+
+	best, bestCost := -1, squaredError(logs)
+	for split := minScheduleSegment; split <= len(logs)-minScheduleSegment; split++ {
+		if cost := squaredError(logs[:split]) + squaredError(logs[split:]); cost < bestCost {
+			best, bestCost = split, cost
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+
+	before, after := Median(days[lo:lo+best]), Median(days[lo+best:hi])
+	if math.Max(before, after) < scheduleChangeRatio*math.Min(before, after) {
+		return nil
+	}
+
+	splits := scheduleSplits(days, lo, lo+best, depth+1)
+	splits = append(splits, lo+best)
+	return append(splits, scheduleSplits(days, lo+best, hi, depth+1)...)
+}
+
+// squaredError is the sum of squared deviations of values from their mean
+func squaredError(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return squares
+}
+
+// nextSplit returns the split after split, or end if it is the last one
+func nextSplit(splits []int, split, end int) int {
+	for _, s := range splits {
+		if s > split {
+			return s
+		}
+	}
+	return end
+}
@@ -0,0 +1,181 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// uploads returns videos v00, v01, ... published at the given times. views gives each
+// video's view count; without it every video has 100.
+func uploads(times []time.Time, views []int64) []models.Video {
+	videos := make([]models.Video, len(times))
+	for i, t := range times {
+		videos[i] = models.Video{ID: fmt.Sprintf("v%02d", i), Views: 100, PublishedAt: t}
+		if views != nil {
+			videos[i].Views = views[i]
+		}
+	}
+	return videos
+}
+
+// spaced returns n upload times starting at start, each gap days after the last
+func spaced(start time.Time, n int, gap float64) []time.Time {
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = start.Add(time.Duration(float64(i) * gap * float64(oneDay)))
+	}
+	return times
+}
+
+func TestComputeCadenceEvenSchedule(t *testing.T) {
+	// Wednesday uploads for 20 weeks, the last one this week
+	now := time.Date(2024, 5, 17, 12, 0, 0, 0, time.UTC)
+	times := spaced(time.Date(2023, 12, 27, 15, 0, 0, 0, time.UTC), 21, 7)
+	cadence := ComputeCadence(&models.Channel{ID: "UC1"}, uploads(times, nil), now)
+
+	if math.Abs(cadence.ConsistencyScore-100) > 1e-9 {
+		t.Errorf("consistency = %v, want 100", cadence.ConsistencyScore)
+	}
+	if cadence.MedianGapDays != 7 || cadence.MeanGapDays != 7 {
+		t.Errorf("gaps = median %v mean %v, want 7", cadence.MedianGapDays, cadence.MeanGapDays)
+	}
+	if len(cadence.ScheduleChanges) != 0 {
+		t.Errorf("schedule changes = %v, want none", cadence.ScheduleChanges)
+	}
+	if cadence.CurrentStreak.Weeks != 21 || cadence.LongestStreak.Weeks != 21 {
+		t.Errorf("streaks = current %d longest %d, want 21", cadence.CurrentStreak.Weeks, cadence.LongestStreak.Weeks)
+	}
+	// 2023-12-27 falls in the last ISO week of 2023
+	if cadence.LongestStreak.StartWeek != "2023-W52" || cadence.LongestStreak.EndWeek != "2024-W20" {
+		t.Errorf("longest streak = %s to %s, want 2023-W52 to 2024-W20", cadence.LongestStreak.StartWeek, cadence.LongestStreak.EndWeek)
+	}
+	if want := times[len(times)-1].Add(oneWeek); !cadence.NextExpectedUpload.Equal(want) {
+		t.Errorf("next expected upload = %v, want %v", cadence.NextExpectedUpload, want)
+	}
+	if cadence.Overdue {
+		t.Errorf("overdue = true, want false")
+	}
+}
+
+func TestComputeCadenceScheduleChange(t *testing.T) {
+	// Ten weekly gaps, then ten daily ones
+	weekly := spaced(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), 11, 7)
+	daily := spaced(weekly[10].Add(oneDay), 10, 1)
+	times := append(weekly, daily...)
+	now := daily[len(daily)-1].Add(3 * oneDay)
+
+	cadence := ComputeCadence(&models.Channel{ID: "UC1"}, uploads(times, nil), now)
+
+	if len(cadence.ScheduleChanges) != 1 {
+		t.Fatalf("schedule changes = %+v, want one", cadence.ScheduleChanges)
+	}
+	change := cadence.ScheduleChanges[0]
+	if !change.Date.Equal(weekly[10]) {
+		t.Errorf("change date = %v, want %v", change.Date, weekly[10])
+	}
+	if change.MedianGapDaysBefore != 7 || change.MedianGapDaysAfter != 1 {
+		t.Errorf("medians = %v before, %v after, want 7 and 1", change.MedianGapDaysBefore, change.MedianGapDaysAfter)
+	}
+	// The next upload is expected on the daily schedule, so three days on it is overdue
+	if !cadence.Overdue {
+		t.Errorf("overdue = false, want true")
+	}
+}
+
+func TestComputeCadenceShortSegmentsDoNotSplit(t *testing.T) {
+	// Two long gaps then eleven short ones: too few on one side to call it a change
+	long := spaced(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 3, 30)
+	short := spaced(long[2].Add(oneDay), 11, 1)
+	times := append(long, short...)
+
+	cadence := ComputeCadence(&models.Channel{ID: "UC1"}, uploads(times, nil), short[9])
+	if len(cadence.ScheduleChanges) != 0 {
+		t.Errorf("schedule changes = %+v, want none", cadence.ScheduleChanges)
+	}
+}
+
+func TestWeeklyStreaks(t *testing.T) {
+	monday := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 10, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name                  string
+		uploads               []time.Time
+		now                   time.Time
+		wantCurrent, wantLong int
+	}{
+		{
+			name:        "single upload this week",
+			uploads:     []time.Time{monday(2024, 3, 4)},
+			now:         monday(2024, 3, 6),
+			wantCurrent: 1,
+			wantLong:    1,
+		},
+		{
+			name:        "streak survives a week without an upload yet",
+			uploads:     []time.Time{monday(2024, 2, 19), monday(2024, 2, 26)},
+			now:         monday(2024, 3, 6),
+			wantCurrent: 2,
+			wantLong:    2,
+		},
+		{
+			name:        "streak ends after a full missed week",
+			uploads:     []time.Time{monday(2024, 2, 12), monday(2024, 2, 19)},
+			now:         monday(2024, 3, 6),
+			wantCurrent: 0,
+			wantLong:    2,
+		},
+		{
+			name: "longest streak before a break",
+			uploads: []time.Time{
+				monday(2024, 1, 1), monday(2024, 1, 8), monday(2024, 1, 15),
+				monday(2024, 2, 5), monday(2024, 2, 12),
+			},
+			now:         monday(2024, 2, 14),
+			wantCurrent: 2,
+			wantLong:    3,
+		},
+		{
+			// 2020 has 53 ISO weeks; W53 and 2021-W01 are consecutive
+			name:        "across the year boundary",
+			uploads:     []time.Time{monday(2020, 12, 28), monday(2021, 1, 4)},
+			now:         monday(2021, 1, 5),
+			wantCurrent: 2,
+			wantLong:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest := weeklyStreaks(Chronological(uploads(tt.uploads, nil)), tt.now)
+			if current.Weeks != tt.wantCurrent || longest.Weeks != tt.wantLong {
+				t.Errorf("streaks = current %d longest %d, want %d and %d",
+					current.Weeks, longest.Weeks, tt.wantCurrent, tt.wantLong)
+			}
+		})
+	}
+}
+
+func TestComputeCadenceFewUploads(t *testing.T) {
+	now := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	empty := ComputeCadence(&models.Channel{ID: "UC1"}, nil, now)
+	if empty.FirstUpload != nil || empty.LongestHiatus != nil || empty.NextExpectedUpload != nil {
+		t.Errorf("empty channel = %+v, want no dates", empty)
+	}
+
+	single := ComputeCadence(&models.Channel{ID: "UC1"}, uploads([]time.Time{now.Add(-oneDay)}, nil), now)
+	if single.FirstUpload == nil || single.LongestHiatus != nil || single.NextExpectedUpload != nil {
+		t.Errorf("single upload = %+v, want a first upload and no gaps", single)
+	}
+
+	// Same-second uploads leave a zero gap, which must not divide by zero
+	same := ComputeCadence(&models.Channel{ID: "UC1"}, uploads([]time.Time{now, now, now}, nil), now)
+	if math.IsNaN(same.ConsistencyScore) || same.ConsistencyScore != 0 {
+		t.Errorf("consistency of zero gaps = %v, want 0", same.ConsistencyScore)
+	}
+}
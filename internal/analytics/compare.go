@@ -0,0 +1,150 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// comparisonMetrics lists the compared metrics in the order they are reported
+var comparisonMetrics = []string{
+	models.MetricSubscribers,
+	models.MetricViews,
+	models.MetricVideos,
+	models.MetricAverageViews,
+	models.MetricLikeToViewRatio,
+	models.MetricCommentToViewRatio,
+	models.MetricViewsPerSubscriber,
+	models.MetricAverageViewsPerSubscriber,
+	models.MetricViewsPerVideo,
+	models.MetricLikesPerVideo,
+	models.MetricCommentsPerVideo,
+}
+
+// ComputeComparison compares channels side by side; videos[i] holds the uploads of
+// channels[i]. Channel totals come from the channel statistics and per-video
+// averages from the uploads. Percent differences are measured from the first channel.
+func ComputeComparison(channels []*models.Channel, videos [][]models.Video, now time.Time) *models.ChannelComparison {
+	comparison := &models.ChannelComparison{
+		Metrics:   comparisonMetrics,
+		Months:    []string{},
+		Channels:  make([]models.ComparedChannel, len(channels)),
+		Timestamp: now,
+	}
+	if len(channels) == 0 {
+		return comparison
+	}
+	comparison.BaseChannelID = channels[0].ID
+
+	values := make([]map[string]float64, len(channels))
+	var first, last time.Time
+	for i, channel := range channels {
+		values[i] = comparisonValues(channel, videos[i])
+		if start, end, ok := publishedBounds(videos[i]); ok {
+			if first.IsZero() || start.Before(first) {
+				first = start
+			}
+			if end.After(last) {
+				last = end
+			}
+		}
+	}
+	var months []Period
+	if !first.IsZero() {
+		months = PeriodRange(first, last, GranularityMonth, time.UTC)
+		for _, month := range months {
+			comparison.Months = append(comparison.Months, month.Key)
+		}
+	}
+
+	for i, channel := range channels {
+		metrics := make(map[string]models.ComparisonMetric, len(comparisonMetrics))
+		for _, metric := range comparisonMetrics {
+			value := values[i][metric]
+			result := models.ComparisonMetric{Value: value, Rank: 1}
+			for j := range channels {
+				if values[j][metric] > value {
+					result.Rank++
+				}
+			}
+			if base := values[0][metric]; base != 0 {
+				diff := (value - base) / base * 100
+				result.PercentDiff = &diff
+			}
+			metrics[metric] = result
+		}
+
+		comparison.Channels[i] = models.ComparedChannel{
+			ChannelID:    channel.ID,
+			ChannelTitle: channel.Title,
+			Thumbnail:    channel.Thumbnail,
+			TotalVideos:  len(videos[i]),
+			Metrics:      metrics,
+			Monthly:      alignedMonthly(videos[i], months),
+		}
+	}
+	return comparison
+}
+
+// comparisonValues computes every compared metric of one channel
+func comparisonValues(channel *models.Channel, videos []models.Video) map[string]float64 {
+	views, likes, comments := Totals(videos)
+	values := map[string]float64{
+		models.MetricSubscribers: float64(channel.Subscribers),
+		models.MetricViews:       float64(channel.ViewCount),
+		models.MetricVideos:      float64(channel.VideoCount),
+	}
+
+	if n := float64(len(videos)); n > 0 {
+		values[models.MetricAverageViews] = float64(views) / n
+		values[models.MetricLikesPerVideo] = float64(likes) / n
+		values[models.MetricCommentsPerVideo] = float64(comments) / n
+	}
+	if views > 0 {
+		values[models.MetricLikeToViewRatio] = float64(likes) / float64(views)
+		values[models.MetricCommentToViewRatio] = float64(comments) / float64(views)
+	}
+	if channel.Subscribers > 0 {
+		subscribers := float64(channel.Subscribers)
+		values[models.MetricViewsPerSubscriber] = float64(channel.ViewCount) / subscribers
+		values[models.MetricAverageViewsPerSubscriber] = values[models.MetricAverageViews] / subscribers
+	}
+	if channel.VideoCount > 0 {
+		values[models.MetricViewsPerVideo] = float64(channel.ViewCount) / float64(channel.VideoCount)
+	}
+	return values
+}
+
+// publishedBounds returns the earliest and latest publish times of the videos
+func publishedBounds(videos []models.Video) (first, last time.Time, ok bool) {
+	for i, v := range videos {
+		if i == 0 || v.PublishedAt.Before(first) {
+			first = v.PublishedAt
+		}
+		if i == 0 || v.PublishedAt.After(last) {
+			last = v.PublishedAt
+		}
+	}
+	return first, last, len(videos) > 0
+}
+
+// alignedMonthly summarizes a channel's uploads for each of the given months,
+// with zeros for months it did not upload in
+func alignedMonthly(videos []models.Video, months []Period) []models.MonthlyComparison {
+	groups := groupByPeriod(videos, GranularityMonth, time.UTC)
+
+	monthly := make([]models.MonthlyComparison, 0, len(months))
+	for _, month := range months {
+		point := models.MonthlyComparison{Period: month.Key, PeriodStart: month.Start, PeriodEnd: month.End}
+		if group := groups[month.Key]; len(group) > 0 {
+			views, likes, comments := Totals(group)
+			n := float64(len(group))
+			point.Uploads = len(group)
+			point.AverageViews = float64(views) / n
+			point.AverageLikes = float64(likes) / n
+			point.AverageComments = float64(comments) / n
+		}
+		monthly = append(monthly, point)
+	}
+	return monthly
+}
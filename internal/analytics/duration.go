@@ -0,0 +1,115 @@
+package analytics
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// isoDurationPattern matches the ISO 8601 durations YouTube reports, e.g. "PT1H2M3S" or "P1DT2H"
+var isoDurationPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ParseISODuration parses a video duration such as "PT4M13S"
+func ParseISODuration(value string) (time.Duration, error) {
+	match := isoDurationPattern.FindStringSubmatch(value)
+	// The pattern also admits a bare "P" and a "T" with no time after it
+	if match == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q", value)
+	}
+
+	var total time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q: %v", value, err)
+		}
+		total += time.Duration(n) * unit
+	}
+	return total, nil
+}
+
+// durationBuckets are the video lengths performance is reported by; a max of 0 means no upper bound
+var durationBuckets = []struct {
+	label    string
+	min, max time.Duration
+}{
+	{"<1m", 0, time.Minute},
+	{"1-4m", time.Minute, 4 * time.Minute},
+	{"4-10m", 4 * time.Minute, 10 * time.Minute},
+	{"10-20m", 10 * time.Minute, 20 * time.Minute},
+	{"20-40m", 20 * time.Minute, 40 * time.Minute},
+	{"40m+", 40 * time.Minute, 0},
+}
+
+// DurationBuckets reports performance by video length. Every bucket is listed, empty
+// or not; each one's trend runs monthly from its first upload to its last. Videos with
+// no usable duration, such as upcoming live streams, are left out.
+func DurationBuckets(videos []models.Video) []models.DurationBucket {
+	groups := make([][]models.Video, len(durationBuckets))
+	for _, v := range videos {
+		length, err := ParseISODuration(v.Duration)
+		if err != nil || length == 0 {
+			continue
+		}
+		for i, b := range durationBuckets {
+			if length >= b.min && (b.max == 0 || length < b.max) {
+				groups[i] = append(groups[i], v)
+				break
+			}
+		}
+	}
+
+	buckets := make([]models.DurationBucket, 0, len(durationBuckets))
+	for i, b := range durationBuckets {
+		group := groups[i]
+		views, likes, comments := Totals(group)
+
+		result := models.DurationBucket{
+			Bucket:      b.label,
+			MinSeconds:  int(b.min.Seconds()),
+			MaxSeconds:  int(b.max.Seconds()),
+			Videos:      len(group),
+			MedianViews: medianViews(group),
+			Trend:       durationTrend(group),
+		}
+		if views > 0 {
+			result.LikeToViewRatio = float64(likes) / float64(views)
+			result.CommentToViewRatio = float64(comments) / float64(views)
+		}
+		buckets = append(buckets, result)
+	}
+	return buckets
+}
+
+// durationTrend returns the uploads and median views of a bucket per calendar month in UTC,
+// including months without uploads
+func durationTrend(videos []models.Video) []models.DurationTrendPoint {
+	periods, groups := periodGroups(videos, GranularityMonth, time.UTC)
+	trend := make([]models.DurationTrendPoint, 0, len(periods))
+	for _, p := range periods {
+		trend = append(trend, models.DurationTrendPoint{
+			Period:      p.Key,
+			PeriodStart: p.Start,
+			PeriodEnd:   p.End,
+			Videos:      len(groups[p.Key]),
+			MedianViews: medianViews(groups[p.Key]),
+		})
+	}
+	return trend
+}
+
+// medianViews returns the median views of the videos
+func medianViews(videos []models.Video) float64 {
+	views := make([]float64, len(videos))
+	for i, v := range videos {
+		views[i] = float64(v.Views)
+	}
+	return Median(views)
+}
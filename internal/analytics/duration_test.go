@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+func TestParseISODuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "PT4M13S", want: 4*time.Minute + 13*time.Second},
+		{value: "PT1H", want: time.Hour},
+		{value: "PT1H2M3S", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{value: "PT59S", want: 59 * time.Second},
+		{value: "P1DT2H", want: 26 * time.Hour},
+		{value: "P1D", want: 24 * time.Hour},
+		{value: "P0D", want: 0},
+		{value: "PT0S", want: 0},
+		{value: "PT90M", want: 90 * time.Minute},
+		{value: "", wantErr: true},
+		{value: "P", wantErr: true},
+		{value: "PT", wantErr: true},
+		{value: "P1DT", wantErr: true},
+		{value: "4M13S", wantErr: true},
+		{value: "PT4.5S", wantErr: true},
+		{value: "PT1S2M", wantErr: true},
+		{value: "pt1m", wantErr: true},
+		{value: "PT99999999999999999999S", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseISODuration(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseISODuration(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseISODuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDurationBuckets(t *testing.T) {
+	published := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	video := func(id, duration string, views int64, months int) models.Video {
+		return models.Video{ID: id, Duration: duration, Views: views, PublishedAt: published.AddDate(0, months, 0)}
+	}
+	videos := []models.Video{
+		video("short", "PT59S", 100, 0),
+		video("minute", "PT1M", 200, 0),
+		video("edge", "PT3M59S", 300, 0),
+		video("four", "PT4M", 400, 0),
+		video("long", "PT40M", 500, 0),
+		video("day", "P1D", 600, 3),
+		video("upcoming", "P0D", 700, 0),
+		video("broken", "PT", 800, 0),
+	}
+
+	buckets := DurationBuckets(videos)
+	want := map[string]int{"<1m": 1, "1-4m": 2, "4-10m": 1, "10-20m": 0, "20-40m": 0, "40m+": 2}
+	if len(buckets) != len(want) {
+		t.Fatalf("got %d buckets, want %d", len(buckets), len(want))
+	}
+	for _, b := range buckets {
+		if b.Videos != want[b.Bucket] {
+			t.Errorf("bucket %s has %d videos, want %d", b.Bucket, b.Videos, want[b.Bucket])
+		}
+	}
+
+	// 40m+ has uploads in January and April, so its trend fills February and March
+	trend := buckets[5].Trend
+	if len(trend) != 4 {
+		t.Fatalf("40m+ trend = %+v, want four months", trend)
+	}
+	for i, period := range []string{"2024-01", "2024-02", "2024-03", "2024-04"} {
+		if trend[i].Period != period {
+			t.Errorf("trend[%d] = %s, want %s", i, trend[i].Period, period)
+		}
+	}
+	if trend[1].Videos != 0 || trend[1].MedianViews != 0 {
+		t.Errorf("gap month = %+v, want empty", trend[1])
+	}
+	if buckets[3].Trend == nil || len(buckets[3].Trend) != 0 {
+		t.Errorf("empty bucket trend = %v, want an empty list", buckets[3].Trend)
+	}
+}
@@ -0,0 +1,353 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// ForecastHorizons are the number of days ahead every forecast projects
+var ForecastHorizons = []int{30, 90, 365}
+
+const (
+	// MinForecastSnapshots is the fewest daily snapshots a forecast is fitted to
+	MinForecastSnapshots = 3
+	// ForecastConfidence is the coverage of the prediction intervals
+	ForecastConfidence = 0.95
+
+	// forecastZ is the normal quantile for ForecastConfidence
+	forecastZ = 1.96
+	// maxMilestoneDays is how far ahead a milestone is searched for
+	maxMilestoneDays = 3650
+	// minBacktestSnapshots is the fewest snapshots a model is fitted to before
+	// it is scored on predicting the next one
+	minBacktestSnapshots = 2
+)
+
+// forecaster is a model fitted to a daily series
+type forecaster interface {
+	// predict projects the series h days past its last point
+	predict(h int) (value, lower, upper float64)
+}
+
+// forecastModel fits one kind of model to a series, returning nil if it does not apply
+type forecastModel struct {
+	name string
+	fit  func(days, values []float64) forecaster
+}
+
+// forecastModels are the models every metric is fitted with, in order of preference on ties
+var forecastModels = []forecastModel{
+	{models.ForecastLinear, func(days, values []float64) forecaster {
+		return fitLinear(days, values)
+	}},
+	{models.ForecastLogLinear, func(days, values []float64) forecaster {
+		if m := fitLogLinear(days, values); m != nil {
+			return m
+		}
+		return nil
+	}},
+	{models.ForecastHolt, func(days, values []float64) forecaster {
+		return fitHolt(days, values)
+	}},
+}
+
+// ComputeForecast projects a channel's subscribers and total views from its
+// snapshots, oldest first, with linear regression, log-linear regression and
+// Holt's linear exponential smoothing. Horizons are counted from the latest
+// snapshot and intervals use a normal approximation. The best model is the one
+// that predicted each recorded snapshot from the ones before it most closely.
+func ComputeForecast(channel *models.Channel, snapshots []models.ChannelSnapshot, now time.Time) *models.ChannelForecast {
+	forecast := &models.ChannelForecast{
+		ChannelID:    channel.ID,
+		ChannelTitle: channel.Title,
+		Snapshots:    len(snapshots),
+		Confidence:   ForecastConfidence,
+		Timestamp:    now,
+	}
+	if len(snapshots) > 0 {
+		from, to := snapshots[0].SnapshotDate, snapshots[len(snapshots)-1].SnapshotDate
+		forecast.From, forecast.To = &from, &to
+	}
+	if len(snapshots) < MinForecastSnapshots {
+		forecast.Message = fmt.Sprintf("At least %d daily snapshots are needed to forecast; %d recorded so far", MinForecastSnapshots, len(snapshots))
+		return forecast
+	}
+
+	days := make([]float64, len(snapshots))
+	subscribers := make([]float64, len(snapshots))
+	views := make([]float64, len(snapshots))
+	for i, s := range snapshots {
+		days[i] = s.SnapshotDate.Sub(snapshots[0].SnapshotDate).Hours() / 24
+		subscribers[i] = float64(s.Subscribers)
+		views[i] = float64(s.ViewCount)
+	}
+	last := snapshots[len(snapshots)-1]
+
+	var subscriberModels map[string]forecaster
+	forecast.Subscribers, subscriberModels = forecastMetric("subscribers", last.Subscribers, days, subscribers, last.SnapshotDate)
+	forecast.Views, _ = forecastMetric("views", last.ViewCount, days, views, last.SnapshotDate)
+
+	milestone := NextMilestone(last.Subscribers)
+	eta := &models.MilestoneETA{Milestone: milestone, Model: forecast.Subscribers.BestModel}
+	best := subscriberModels[forecast.Subscribers.BestModel]
+	for h := 1; h <= maxMilestoneDays; h++ {
+		if value, _, _ := best.predict(h); value >= float64(milestone) {
+			date := last.SnapshotDate.AddDate(0, 0, h)
+			daysAway := h
+			eta.Date, eta.DaysAway = &date, &daysAway
+			break
+		}
+	}
+	forecast.SubscriberMilestone = eta
+
+	return forecast
+}
+
+// forecastMetric fits every model to one series and projects it over ForecastHorizons
+func forecastMetric(metric string, current int64, days, values []float64, lastDate time.Time) (*models.MetricForecast, map[string]forecaster) {
+	fitted := make(map[string]forecaster, len(forecastModels))
+	result := &models.MetricForecast{Metric: metric, Current: current}
+	bestRMSE := math.Inf(1)
+	for _, fm := range forecastModels {
+		model := fm.fit(days, values)
+		if model == nil {
+			continue
+		}
+		fitted[fm.name] = model
+		rmse := backtest(fm, days, values)
+
+		points := make([]models.ForecastPoint, 0, len(ForecastHorizons))
+		for _, h := range ForecastHorizons {
+			value, lower, upper := model.predict(h)
+			points = append(points, models.ForecastPoint{
+				HorizonDays: h,
+				Date:        lastDate.AddDate(0, 0, h),
+				Value:       math.Max(value, 0),
+				Lower:       math.Max(lower, 0),
+				Upper:       math.Max(upper, 0),
+			})
+		}
+		result.Models = append(result.Models, models.ModelForecast{Model: fm.name, RMSE: rmse, Points: points})
+
+		if rmse < bestRMSE {
+			result.BestModel, bestRMSE = fm.name, rmse
+		}
+	}
+	return result, fitted
+}
+
+// backtest refits a model to every prefix of the series and returns the root mean
+// squared error of its predictions of the snapshot that followed. Every model is
+// scored on the same recorded snapshots, so the errors compare.
+func backtest(fm forecastModel, days, values []float64) float64 {
+	var sse float64
+	var n int
+	for k := minBacktestSnapshots; k < len(days); k++ {
+		model := fm.fit(days[:k], values[:k])
+		if model == nil {
+			return math.Inf(1)
+		}
+		predicted, _, _ := model.predict(int(math.Round(days[k] - days[k-1])))
+		sse += (values[k] - predicted) * (values[k] - predicted)
+		n++
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Sqrt(sse / float64(n))
+}
+
+// NextMilestone returns the next subscriber count on the 1, 2, 5 series above current
+func NextMilestone(current int64) int64 {
+	for scale := int64(1000); ; scale *= 10 {
+		for _, step := range []int64{1, 2, 5} {
+			if step*scale > current {
+				return step * scale
+			}
+		}
+	}
+}
+
+// regression is an ordinary least squares fit of y on x
+type regression struct {
+	intercept, slope float64
+	// se is the residual standard error; n, meanX, sxx and lastX size the prediction interval
+	se, n, meanX, sxx, lastX float64
+}
+
+// fitRegression fits y = intercept + slope*x
+func fitRegression(x, y []float64) regression {
+	n := float64(len(x))
+	var sumX, sumY float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy float64
+	for i := range x {
+		sxx += (x[i] - meanX) * (x[i] - meanX)
+		sxy += (x[i] - meanX) * (y[i] - meanY)
+	}
+	slope := 0.0
+	if sxx > 0 {
+		slope = sxy / sxx
+	}
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i := range x {
+		r := y[i] - (intercept + slope*x[i])
+		sse += r * r
+	}
+
+	return regression{
+		intercept: intercept,
+		slope:     slope,
+		se:        math.Sqrt(sse / math.Max(n-2, 1)),
+		n:         n,
+		meanX:     meanX,
+		sxx:       sxx,
+		lastX:     x[len(x)-1],
+	}
+}
+
+// predict returns the fitted value and the half width of its prediction interval h days ahead
+func (r regression) predict(h int) (float64, float64) {
+	x := r.lastX + float64(h)
+	spread := 1 + 1/r.n
+	if r.sxx > 0 {
+		spread += (x - r.meanX) * (x - r.meanX) / r.sxx
+	}
+	return r.intercept + r.slope*x, forecastZ * r.se * math.Sqrt(spread)
+}
+
+// linearModel extrapolates a straight line through the series
+type linearModel struct {
+	fit regression
+}
+
+func fitLinear(days, values []float64) *linearModel {
+	return &linearModel{fit: fitRegression(days, values)}
+}
+
+func (m *linearModel) predict(h int) (float64, float64, float64) {
+	value, width := m.fit.predict(h)
+	return value, value - width, value + width
+}
+
+// logLinearModel extrapolates constant percentage growth
+type logLinearModel struct {
+	fit regression
+}
+
+// fitLogLinear fits a straight line to the logarithm of the series, or returns
+// nil if the series is not strictly positive
+func fitLogLinear(days, values []float64) *logLinearModel {
+	logs := make([]float64, len(values))
+	for i, v := range values {
+		if v <= 0 {
+			return nil
+		}
+		logs[i] = math.Log(v)
+	}
+
+	return &logLinearModel{fit: fitRegression(days, logs)}
+}
+
+func (m *logLinearModel) predict(h int) (float64, float64, float64) {
+	value, width := m.fit.predict(h)
+	return math.Exp(value), math.Exp(value - width), math.Exp(value + width)
+}
+
+// holtModel is Holt's linear exponential smoothing, stepped from snapshot to
+// snapshot with the trend counted per day
+type holtModel struct {
+	alpha, beta  float64
+	level, trend float64
+	// sigma is the one-day-ahead error, estimated at the recorded snapshots only
+	sigma float64
+}
+
+// fitHolt picks the smoothing parameters whose one-step-ahead predictions of the
+// recorded snapshots have the lowest squared error. Gaps between snapshots are
+// stepped over rather than filled in, since interpolated days would be predicted
+// almost perfectly and shrink the error.
+func fitHolt(days, values []float64) *holtModel {
+	if days[len(days)-1] <= days[0] {
+		// Every snapshot is from the same day, so there is no trend to smooth
+		return &holtModel{alpha: 1, beta: 1, level: values[len(values)-1]}
+	}
+
+	var best *holtModel
+	bestSSE, bestN := math.Inf(1), 0
+	for a := 1; a <= 9; a++ {
+		for b := 1; b <= 9; b++ {
+			m := &holtModel{alpha: float64(a) / 10, beta: float64(b) / 10}
+			if sse, n := m.smooth(days, values); sse < bestSSE {
+				best, bestSSE, bestN = m, sse, n
+			}
+		}
+	}
+	if bestN > 0 {
+		best.sigma = math.Sqrt(bestSSE / float64(bestN))
+	}
+	return best
+}
+
+// smooth runs the model over the snapshots, leaving the final level and trend, and
+// returns the sum of squared one-step-ahead errors and how many were measured. Each
+// error is scaled by the spread of a forecast as many days ahead as the gap it spans,
+// so the sum estimates the one-day error whatever the spacing of the snapshots.
+func (m *holtModel) smooth(days, values []float64) (float64, int) {
+	// The trend starts from the first snapshot on a later day, which is not scored
+	first := 1
+	for days[first] <= days[0] {
+		first++
+	}
+	m.level = values[0]
+	m.trend = (values[first] - values[0]) / (days[first] - days[0])
+
+	var sse float64
+	var n int
+	for i := 1; i < len(days); i++ {
+		gap := days[i] - days[i-1]
+		if gap <= 0 {
+			// A second snapshot from the same day replaces the first
+			m.level = values[i]
+			continue
+		}
+
+		predicted := m.level + gap*m.trend
+		if i > first {
+			e := values[i] - predicted
+			sse += e * e / m.spread(int(math.Round(gap)))
+			n++
+		}
+
+		level := m.alpha*values[i] + (1-m.alpha)*predicted
+		m.trend = m.beta*(level-m.level)/gap + (1-m.beta)*m.trend
+		m.level = level
+	}
+	return sse, n
+}
+
+// spread is the variance of an h-day-ahead forecast relative to a one-day one
+func (m *holtModel) spread(h int) float64 {
+	variance := 1.0
+	for j := 1; j < h; j++ {
+		c := m.alpha * (1 + float64(j)*m.beta)
+		variance += c * c
+	}
+	return variance
+}
+
+func (m *holtModel) predict(h int) (float64, float64, float64) {
+	value := m.level + float64(h)*m.trend
+	width := forecastZ * m.sigma * math.Sqrt(m.spread(h))
+	return value, value - width, value + width
+}
@@ -0,0 +1,205 @@
+package analytics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// snapshotsOn returns channel snapshots on the given day offsets with the given subscribers;
+// total views are a thousand times the subscribers
+func snapshotsOn(days []int, subscribers []int64) []models.ChannelSnapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := make([]models.ChannelSnapshot, len(days))
+	for i, d := range days {
+		snapshots[i] = models.ChannelSnapshot{
+			ChannelID:    "UC1",
+			SnapshotDate: start.AddDate(0, 0, d),
+			Subscribers:  subscribers[i],
+			ViewCount:    subscribers[i] * 1000,
+		}
+	}
+	return snapshots
+}
+
+// modelForecast returns the named model's projection, or nil if it was not fitted
+func modelForecast(metric *models.MetricForecast, name string) *models.ModelForecast {
+	for i := range metric.Models {
+		if metric.Models[i].Model == name {
+			return &metric.Models[i]
+		}
+	}
+	return nil
+}
+
+func TestComputeForecastTooFewSnapshots(t *testing.T) {
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"}, snapshotsOn([]int{0, 1}, []int64{10, 20}), time.Now())
+	if forecast.Message == "" || forecast.Subscribers != nil || forecast.SubscriberMilestone != nil {
+		t.Errorf("forecast = %+v, want only a message", forecast)
+	}
+	if forecast.From == nil || forecast.To == nil {
+		t.Errorf("forecast range missing for two snapshots")
+	}
+}
+
+func TestComputeForecastLinearGrowth(t *testing.T) {
+	// Ten subscribers a day; 1000 is 7.5 days past the last snapshot
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"}, snapshotsOn([]int{0, 1, 2}, []int64{905, 915, 925}), time.Now())
+
+	linear := modelForecast(forecast.Subscribers, models.ForecastLinear)
+	if linear == nil {
+		t.Fatal("no linear forecast")
+	}
+	if linear.RMSE > 1e-6 {
+		t.Errorf("linear RMSE = %v, want 0", linear.RMSE)
+	}
+	for _, p := range linear.Points {
+		want := 925 + 10*float64(p.HorizonDays)
+		if math.Abs(p.Value-want) > 1e-6 {
+			t.Errorf("linear value at %d days = %v, want %v", p.HorizonDays, p.Value, want)
+		}
+	}
+
+	eta := forecast.SubscriberMilestone
+	if eta == nil || eta.Milestone != 1000 || eta.DaysAway == nil || *eta.DaysAway != 8 {
+		t.Fatalf("milestone = %+v, want 1000 in 8 days", eta)
+	}
+	if want := time.Date(2024, 1, 11, 0, 0, 0, 0, time.UTC); !eta.Date.Equal(want) {
+		t.Errorf("milestone date = %v, want %v", eta.Date, want)
+	}
+}
+
+func TestComputeForecastExponentialGrowth(t *testing.T) {
+	days := make([]int, 10)
+	subscribers := make([]int64, 10)
+	for i := range days {
+		days[i] = i
+		subscribers[i] = int64(math.Round(10000 * math.Pow(1.1, float64(i))))
+	}
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"}, snapshotsOn(days, subscribers), time.Now())
+
+	if forecast.Subscribers.BestModel != models.ForecastLogLinear {
+		t.Errorf("best model = %s, want %s", forecast.Subscribers.BestModel, models.ForecastLogLinear)
+	}
+}
+
+func TestComputeForecastFlatAndZeroSeries(t *testing.T) {
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"}, snapshotsOn([]int{0, 1, 2, 3}, []int64{0, 0, 0, 0}), time.Now())
+
+	// A log-linear model cannot be fitted to zeros
+	if modelForecast(forecast.Subscribers, models.ForecastLogLinear) != nil {
+		t.Errorf("log-linear model fitted to a zero series")
+	}
+	eta := forecast.SubscriberMilestone
+	if eta.Milestone != 1000 || eta.Date != nil || eta.DaysAway != nil {
+		t.Errorf("milestone = %+v, want 1000 never reached", eta)
+	}
+	for _, m := range forecast.Subscribers.Models {
+		for _, p := range m.Points {
+			if p.Value != 0 || p.Lower != 0 || p.Upper != 0 || math.IsNaN(m.RMSE) {
+				t.Errorf("%s at %d days = %+v, RMSE %v, want zeros", m.Model, p.HorizonDays, p, m.RMSE)
+			}
+		}
+	}
+}
+
+func TestComputeForecastIntervals(t *testing.T) {
+	// Noisy growth with a gap in the snapshots
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"},
+		snapshotsOn([]int{0, 1, 2, 5, 6, 9}, []int64{1000, 1030, 1020, 1100, 1090, 1200}), time.Now())
+
+	for _, m := range forecast.Subscribers.Models {
+		previousWidth := -1.0
+		for _, p := range m.Points {
+			if p.Lower > p.Value || p.Value > p.Upper {
+				t.Errorf("%s at %d days: %v not within [%v, %v]", m.Model, p.HorizonDays, p.Value, p.Lower, p.Upper)
+			}
+			width := p.Upper - p.Lower
+			if width < previousWidth {
+				t.Errorf("%s interval narrows at %d days", m.Model, p.HorizonDays)
+			}
+			previousWidth = width
+		}
+	}
+}
+
+func TestComputeForecastSparseSnapshots(t *testing.T) {
+	// Weekly snapshots of steady growth with noise; filling the gaps in must not
+	// make smoothing look more accurate than the straight line
+	var days []int
+	var subscribers []int64
+	for week := 0; week <= 12; week++ {
+		noise := int64(40)
+		if week%2 == 1 {
+			noise = -40
+		}
+		days = append(days, week*7)
+		subscribers = append(subscribers, 5000+int64(week)*700+noise)
+	}
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"}, snapshotsOn(days, subscribers), time.Now())
+
+	if forecast.Subscribers.BestModel != models.ForecastLinear {
+		t.Errorf("best model = %s, want %s", forecast.Subscribers.BestModel, models.ForecastLinear)
+	}
+
+	// Holt's one-day error is measured only where there are snapshots, so its
+	// interval cannot be narrower than the noise in them
+	holt := modelForecast(forecast.Subscribers, models.ForecastHolt)
+	if holt == nil {
+		t.Fatal("no holt forecast")
+	}
+	if width := holt.Points[0].Upper - holt.Points[0].Lower; width < 2*forecastZ*40 {
+		t.Errorf("holt interval at %d days is %v wide, narrower than the noise", holt.Points[0].HorizonDays, width)
+	}
+}
+
+func TestFitHoltSkipsGaps(t *testing.T) {
+	// Linear growth sampled irregularly is still tracked exactly
+	m := fitHolt([]float64{0, 1, 4, 5, 12}, []float64{0, 3, 12, 15, 36})
+	if value, _, _ := m.predict(3); math.Abs(value-45) > 1e-6 {
+		t.Errorf("holt value 3 days on = %v, want 45", value)
+	}
+	if m.sigma > 1e-9 {
+		t.Errorf("holt sigma = %v, want 0", m.sigma)
+	}
+}
+
+func TestComputeForecastSameDaySnapshots(t *testing.T) {
+	forecast := ComputeForecast(&models.Channel{ID: "UC1"}, snapshotsOn([]int{0, 0, 0}, []int64{10, 20, 30}), time.Now())
+	holt := modelForecast(forecast.Subscribers, models.ForecastHolt)
+	if holt == nil || math.IsNaN(holt.RMSE) {
+		t.Errorf("holt forecast = %+v, want a flat projection", holt)
+	}
+}
+
+func TestNextMilestone(t *testing.T) {
+	tests := []struct {
+		current, want int64
+	}{
+		{0, 1000},
+		{999, 1000},
+		{1000, 2000},
+		{1999, 2000},
+		{2000, 5000},
+		{5000, 10000},
+		{123456, 200000},
+		{999999999, 1000000000},
+	}
+	for _, tt := range tests {
+		if got := NextMilestone(tt.current); got != tt.want {
+			t.Errorf("NextMilestone(%d) = %d, want %d", tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestFitHoltTracksLinearTrend(t *testing.T) {
+	m := fitHolt([]float64{0, 1, 2, 3, 4}, []float64{100, 110, 120, 130, 140})
+	if value, _, _ := m.predict(10); math.Abs(value-240) > 1e-6 {
+		t.Errorf("holt value at 10 days = %v, want 240", value)
+	}
+	if m.sigma > 1e-9 {
+		t.Errorf("holt sigma = %v, want 0", m.sigma)
+	}
+}
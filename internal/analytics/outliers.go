@@ -0,0 +1,150 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// DefaultOutlierWindow is how many neighbouring uploads form a video's baseline
+	DefaultOutlierWindow = 10
+	// DefaultOutlierSensitivity is the robust z-score beyond which a video is an outlier
+	DefaultOutlierSensitivity = 3.5
+
+	// madScale makes the median absolute deviation comparable to a standard deviation
+	madScale = 0.6745
+	// meanADScale does the same for the mean absolute deviation, used when the MAD is 0
+	meanADScale = 0.7979
+	// minOutlierVideos is the fewest uploads a baseline can sensibly be built from
+	minOutlierVideos = 3
+)
+
+// Baselines compares every video with the median views of the window uploads
+// published nearest to it, returned oldest first. The window is centred on the
+// video and shifted inwards at either end of the channel's history.
+//
+// The robust z-score is the modified z-score of log(views/baseline) across the
+// channel, so a video with three times its baseline scores as far above as one
+// with a third of its baseline scores below. When the median absolute deviation
+// is 0 the mean absolute deviation is used instead.
+func Baselines(videos []models.Video, window int) []models.VideoBaseline {
+	ordered := Chronological(videos)
+	if window < 2 {
+		window = 2
+	}
+
+	baselines := make([]models.VideoBaseline, len(ordered))
+	logMultiples := make([]float64, len(ordered))
+	for i, v := range ordered {
+		neighbours := neighbourViews(ordered, i, window)
+		baseline := Median(neighbours)
+
+		multiple := 0.0
+		if baseline > 0 {
+			multiple = float64(v.Views) / baseline
+		}
+		// Smooth by one view so unwatched videos and empty baselines stay finite
+		logMultiples[i] = math.Log((float64(v.Views) + 1) / (baseline + 1))
+
+		baselines[i] = models.VideoBaseline{
+			Video:         v,
+			BaselineViews: baseline,
+			Multiple:      multiple,
+		}
+	}
+
+	median := Median(logMultiples)
+	scale, spread := madScale, MedianAbsoluteDeviation(logMultiples)
+	if spread == 0 {
+		// More than half the videos sit exactly on their baseline, so the MAD says
+		// nothing about the rest; fall back to the mean absolute deviation
+		scale, spread = meanADScale, meanAbsoluteDeviation(logMultiples, median)
+	}
+	if spread > 0 {
+		for i := range baselines {
+			baselines[i].RobustZScore = scale * (logMultiples[i] - median) / spread
+		}
+	}
+	return baselines
+}
+
+// meanAbsoluteDeviation returns the mean distance of values from center
+func meanAbsoluteDeviation(values []float64, center float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += math.Abs(v - center)
+	}
+	return sum / float64(len(values))
+}
+
+// neighbourViews returns the views of up to window uploads around index i, excluding i
+func neighbourViews(ordered []models.Video, i, window int) []float64 {
+	lo := i - window/2
+	hi := lo + window + 1
+	if lo < 0 {
+		hi -= lo
+		lo = 0
+	}
+	if hi > len(ordered) {
+		lo -= hi - len(ordered)
+		hi = len(ordered)
+		if lo < 0 {
+			lo = 0
+		}
+	}
+
+	views := make([]float64, 0, hi-lo)
+	for j := lo; j < hi; j++ {
+		if j != i {
+			views = append(views, float64(ordered[j].Views))
+		}
+	}
+	return views
+}
+
+// ComputeOutliers flags the videos whose robust z-score is at least sensitivity
+// above or below the channel's typical performance. Over-performers are listed
+// strongest first, under-performers weakest first.
+func ComputeOutliers(channel *models.Channel, videos []models.Video, window int, sensitivity float64, now time.Time) *models.ChannelOutliers {
+	result := &models.ChannelOutliers{
+		ChannelID:       channel.ID,
+		ChannelTitle:    channel.Title,
+		TotalVideos:     len(videos),
+		Window:          window,
+		Sensitivity:     sensitivity,
+		Overperforming:  []models.VideoBaseline{},
+		Underperforming: []models.VideoBaseline{},
+		Timestamp:       now,
+	}
+	if len(videos) < minOutlierVideos {
+		return result
+	}
+
+	baselines := Baselines(videos, window)
+	for i, b := range baselines {
+		switch {
+		case b.RobustZScore >= sensitivity:
+			baselines[i].Direction = models.OutlierOver
+			result.Overperforming = append(result.Overperforming, baselines[i])
+		case b.RobustZScore <= -sensitivity:
+			baselines[i].Direction = models.OutlierUnder
+			result.Underperforming = append(result.Underperforming, baselines[i])
+		}
+	}
+
+	sort.SliceStable(result.Overperforming, func(i, j int) bool {
+		return result.Overperforming[i].RobustZScore > result.Overperforming[j].RobustZScore
+	})
+	sort.SliceStable(result.Underperforming, func(i, j int) bool {
+		return result.Underperforming[i].RobustZScore < result.Underperforming[j].RobustZScore
+	})
+
+	result.Videos = baselines
+	return result
+}
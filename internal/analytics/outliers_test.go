@@ -0,0 +1,130 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// outlierStart is when the first upload of every outlier fixture is published
+var outlierStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNeighbourViews(t *testing.T) {
+	videos := uploads(spaced(outlierStart, 10, 1), []int64{0, 1, 2, 3, 4, 5, 6, 7, 8, 9})
+
+	tests := []struct {
+		name      string
+		i, window int
+		want      []float64
+	}{
+		{"centred", 5, 4, []float64{3, 4, 6, 7}},
+		{"shifted at start", 0, 4, []float64{1, 2, 3, 4}},
+		{"shifted at end", 9, 4, []float64{5, 6, 7, 8}},
+		{"window wider than history", 3, 50, []float64{0, 1, 2, 4, 5, 6, 7, 8, 9}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := neighbourViews(videos, tt.i, tt.window)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("neighbourViews(%d, %d) = %v, want %v", tt.i, tt.window, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBaselinesSymmetricScores(t *testing.T) {
+	// Alternating views keep the MAD above zero and every baseline at 1000;
+	// one video triples its baseline and one gets a third of it
+	views := make([]int64, 25)
+	for i := range views {
+		views[i] = 900
+		if i%2 == 1 {
+			views[i] = 1100
+		}
+	}
+	views[5], views[19] = 3000, 333
+	baselines := Baselines(uploads(spaced(outlierStart, len(views), 1), views), 4)
+
+	if got := baselines[5].BaselineViews; got != 1000 {
+		t.Fatalf("baseline of the hit = %v, want 1000", got)
+	}
+	over, under := baselines[5].RobustZScore, baselines[19].RobustZScore
+	if over <= 0 || under >= 0 {
+		t.Fatalf("scores = %.2f and %.2f, want one positive and one negative", over, under)
+	}
+	if math.Abs(over+under) > 0.5 {
+		t.Errorf("scores %.2f and %.2f should be roughly symmetric", over, under)
+	}
+}
+
+func TestComputeOutliers(t *testing.T) {
+	channel := &models.Channel{ID: "UC1"}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		views       []int64
+		wantOver    []string
+		wantUnder   []string
+		wantScored  bool
+		sensitivity float64
+	}{
+		{
+			name:        "too few videos",
+			views:       []int64{10, 100000},
+			sensitivity: DefaultOutlierSensitivity,
+		},
+		{
+			name:        "identical views",
+			views:       []int64{1000, 1000, 1000, 1000, 1000, 1000},
+			sensitivity: DefaultOutlierSensitivity,
+			wantScored:  true,
+		},
+		{
+			// Every other video sits exactly on its baseline, so the MAD is 0
+			name:        "zero MAD still flags a hit",
+			views:       []int64{1000, 1000, 1000, 1000, 1000, 100000, 1000, 1000, 1000, 1000, 1000},
+			sensitivity: DefaultOutlierSensitivity,
+			wantOver:    []string{"v05"},
+			wantScored:  true,
+		},
+		{
+			name:        "zero MAD still flags a flop",
+			views:       []int64{1000, 1000, 1000, 1000, 1000, 10, 1000, 1000, 1000, 1000, 1000},
+			sensitivity: DefaultOutlierSensitivity,
+			wantUnder:   []string{"v05"},
+			wantScored:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ComputeOutliers(channel, uploads(spaced(outlierStart, len(tt.views), 1), tt.views), DefaultOutlierWindow, tt.sensitivity, now)
+
+			if got := videoIDs(result.Overperforming); fmt.Sprint(got) != fmt.Sprint(tt.wantOver) {
+				t.Errorf("overperforming = %v, want %v", got, tt.wantOver)
+			}
+			if got := videoIDs(result.Underperforming); fmt.Sprint(got) != fmt.Sprint(tt.wantUnder) {
+				t.Errorf("underperforming = %v, want %v", got, tt.wantUnder)
+			}
+			if scored := len(result.Videos) > 0; scored != tt.wantScored {
+				t.Errorf("videos scored = %v, want %v", scored, tt.wantScored)
+			}
+			for _, b := range result.Videos {
+				if math.IsNaN(b.RobustZScore) || math.IsInf(b.RobustZScore, 0) {
+					t.Errorf("video %s has score %v", b.Video.ID, b.RobustZScore)
+				}
+			}
+		})
+	}
+}
+
+func videoIDs(baselines []models.VideoBaseline) []string {
+	var ids []string
+	for _, b := range baselines {
+		ids = append(ids, b.Video.ID)
+	}
+	return ids
+}
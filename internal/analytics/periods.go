@@ -0,0 +1,158 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// Granularity is the length of the periods a time series is bucketed by
+type Granularity string
+
+const (
+	GranularityDay     Granularity = "day"
+	GranularityWeek    Granularity = "week"
+	GranularityMonth   Granularity = "month"
+	GranularityQuarter Granularity = "quarter"
+	GranularityYear    Granularity = "year"
+)
+
+// DefaultGranularity is used by trends when none is requested
+const DefaultGranularity = GranularityMonth
+
+// MaxPeriods caps how many periods a requested series may span
+const MaxPeriods = 1000
+
+// Granularities lists every granularity from shortest to longest
+var Granularities = []Granularity{GranularityDay, GranularityWeek, GranularityMonth, GranularityQuarter, GranularityYear}
+
+// ParseGranularity validates a granularity name
+func ParseGranularity(value string) (Granularity, error) {
+	for _, g := range Granularities {
+		if strings.EqualFold(value, string(g)) {
+			return g, nil
+		}
+	}
+	names := make([]string, len(Granularities))
+	for i, g := range Granularities {
+		names[i] = string(g)
+	}
+	return "", fmt.Errorf("unknown granularity %q (expected one of %s)", value, strings.Join(names, ", "))
+}
+
+// Period is one bucket of a time series; End is the exclusive start of the next period
+type Period struct {
+	Key   string
+	Start time.Time
+	End   time.Time
+}
+
+// PeriodOf returns the period containing t, with calendar boundaries taken in loc.
+// Weeks are ISO weeks starting on Monday and keyed like "2024-W05"; days, months,
+// quarters and years are keyed like "2024-01-31", "2024-01", "2024-Q1" and "2024".
+// End is always the Start of the next period, even where a DST change skips midnight.
+func PeriodOf(t time.Time, g Granularity, loc *time.Location) Period {
+	t = t.In(loc)
+	year, month, day := t.Date()
+	nextYear, nextMonth, nextDay := year, month, day
+	switch g {
+	case GranularityDay:
+		nextDay++
+	case GranularityWeek:
+		day -= (int(t.Weekday()) + 6) % 7
+		nextDay = day + 7
+	case GranularityQuarter:
+		month, day = (month-1)/3*3+1, 1
+		nextMonth, nextDay = month+3, 1
+	case GranularityYear:
+		month, day = time.January, 1
+		nextYear, nextMonth, nextDay = year+1, time.January, 1
+	default:
+		day = 1
+		nextMonth, nextDay = month+1, 1
+	}
+
+	// Both boundaries are built from calendar dates so each End is the next Start
+	return Period{
+		Key:   periodKey(time.Date(year, month, day, 0, 0, 0, 0, time.UTC), g),
+		Start: midnight(year, month, day, loc),
+		End:   midnight(nextYear, nextMonth, nextDay, loc),
+	}
+}
+
+// midnight returns the first instant of the given date in loc. Where a clock
+// change skips midnight, time.Date resolves it to the evening before, so the
+// day instead starts when the clocks go forward.
+func midnight(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if want := time.Date(year, month, day, 0, 0, 0, 0, time.UTC); t.Day() != want.Day() {
+		_, t = t.ZoneBounds()
+	}
+	return t
+}
+
+// periodKey names the period starting on the calendar date of start
+func periodKey(start time.Time, g Granularity) string {
+	switch g {
+	case GranularityDay:
+		return start.Format("2006-01-02")
+	case GranularityWeek:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case GranularityQuarter:
+		return fmt.Sprintf("%d-Q%d", start.Year(), (int(start.Month())-1)/3+1)
+	case GranularityYear:
+		return start.Format("2006")
+	default:
+		return start.Format("2006-01")
+	}
+}
+
+// PeriodRange lists every period from the one containing first to the one containing last
+func PeriodRange(first, last time.Time, g Granularity, loc *time.Location) []Period {
+	var periods []Period
+	end := PeriodOf(last, g, loc).End
+	for p := PeriodOf(first, g, loc); p.Start.Before(end); p = PeriodOf(p.End, g, loc) {
+		periods = append(periods, p)
+	}
+	return periods
+}
+
+// CheckPeriods returns an error if the videos' uploads span more than MaxPeriods periods
+func CheckPeriods(videos []models.Video, g Granularity, loc *time.Location) error {
+	first, last, ok := publishedBounds(videos)
+	if !ok {
+		return nil
+	}
+
+	count := 0
+	end := PeriodOf(last, g, loc).End
+	for p := PeriodOf(first, g, loc); p.Start.Before(end); p = PeriodOf(p.End, g, loc) {
+		if count++; count > MaxPeriods {
+			return fmt.Errorf("uploads span more than %d periods of granularity %s; use a coarser granularity", MaxPeriods, g)
+		}
+	}
+	return nil
+}
+
+// groupByPeriod groups videos by the key of the period they were published in
+func groupByPeriod(videos []models.Video, g Granularity, loc *time.Location) map[string][]models.Video {
+	groups := make(map[string][]models.Video)
+	for _, v := range videos {
+		k := PeriodOf(v.PublishedAt, g, loc).Key
+		groups[k] = append(groups[k], v)
+	}
+	return groups
+}
+
+// periodGroups buckets videos by period, returning every period from the first
+// upload to the last, including periods without uploads
+func periodGroups(videos []models.Video, g Granularity, loc *time.Location) ([]Period, map[string][]models.Video) {
+	first, last, ok := publishedBounds(videos)
+	if !ok {
+		return []Period{}, map[string][]models.Video{}
+	}
+	return PeriodRange(first, last, g, loc), groupByPeriod(videos, g, loc)
+}
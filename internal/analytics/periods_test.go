@@ -0,0 +1,247 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("failed to load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParseGranularity(t *testing.T) {
+	for _, value := range []string{"day", "week", "month", "quarter", "year", "WEEK", "Month"} {
+		g, err := ParseGranularity(value)
+		if err != nil || string(g) != strings.ToLower(value) {
+			t.Errorf("ParseGranularity(%q) = %q, %v", value, g, err)
+		}
+	}
+	for _, value := range []string{"", "days", "hour", "fortnight"} {
+		if _, err := ParseGranularity(value); err == nil {
+			t.Errorf("ParseGranularity(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestPeriodOf(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name      string
+		t         time.Time
+		g         Granularity
+		loc       *time.Location
+		wantKey   string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			name:      "day",
+			t:         time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+			g:         GranularityDay,
+			loc:       time.UTC,
+			wantKey:   "2024-01-31",
+			wantStart: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "day in another zone",
+			t:         time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC),
+			g:         GranularityDay,
+			loc:       newYork,
+			wantKey:   "2023-12-31",
+			wantStart: time.Date(2023, 12, 31, 0, 0, 0, 0, newYork),
+			wantEnd:   time.Date(2024, 1, 1, 0, 0, 0, 0, newYork),
+		},
+		{
+			name:      "week starting on a Monday",
+			t:         time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+			g:         GranularityWeek,
+			loc:       time.UTC,
+			wantKey:   "2024-W05",
+			wantStart: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "Sunday belongs to the week before",
+			t:         time.Date(2024, 2, 4, 23, 0, 0, 0, time.UTC),
+			g:         GranularityWeek,
+			loc:       time.UTC,
+			wantKey:   "2024-W05",
+			wantStart: time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "ISO week 53",
+			t:         time.Date(2021, 1, 3, 12, 0, 0, 0, time.UTC),
+			g:         GranularityWeek,
+			loc:       time.UTC,
+			wantKey:   "2020-W53",
+			wantStart: time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "ISO week 1 starting in December",
+			t:         time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC),
+			g:         GranularityWeek,
+			loc:       time.UTC,
+			wantKey:   "2025-W01",
+			wantStart: time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "month",
+			t:         time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC),
+			g:         GranularityMonth,
+			loc:       time.UTC,
+			wantKey:   "2024-02",
+			wantStart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "last quarter",
+			t:         time.Date(2024, 12, 31, 12, 0, 0, 0, time.UTC),
+			g:         GranularityQuarter,
+			loc:       time.UTC,
+			wantKey:   "2024-Q4",
+			wantStart: time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "year",
+			t:         time.Date(2024, 7, 4, 0, 0, 0, 0, time.UTC),
+			g:         GranularityYear,
+			loc:       time.UTC,
+			wantKey:   "2024",
+			wantStart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := PeriodOf(tt.t, tt.g, tt.loc)
+			if p.Key != tt.wantKey || !p.Start.Equal(tt.wantStart) || !p.End.Equal(tt.wantEnd) {
+				t.Errorf("PeriodOf = %s [%v, %v), want %s [%v, %v)",
+					p.Key, p.Start, p.End, tt.wantKey, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestPeriodOfDST(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+
+	// Clocks went forward on 2024-03-10 and back on 2024-11-03
+	spring := PeriodOf(time.Date(2024, 3, 10, 12, 0, 0, 0, newYork), GranularityDay, newYork)
+	if got := spring.End.Sub(spring.Start); got != 23*time.Hour {
+		t.Errorf("spring-forward day lasts %v, want 23h", got)
+	}
+	autumn := PeriodOf(time.Date(2024, 11, 3, 12, 0, 0, 0, newYork), GranularityDay, newYork)
+	if got := autumn.End.Sub(autumn.Start); got != 25*time.Hour {
+		t.Errorf("fall-back day lasts %v, want 25h", got)
+	}
+
+	// The last second before local midnight stays in its day across the change
+	p := PeriodOf(time.Date(2024, 3, 10, 23, 59, 59, 0, newYork), GranularityDay, newYork)
+	if p.Key != "2024-03-10" {
+		t.Errorf("23:59:59 on 2024-03-10 falls in %s", p.Key)
+	}
+}
+
+func TestPeriodRangeContiguous(t *testing.T) {
+	// Sao Paulo skipped midnight when DST started on 2018-11-04
+	zones := []string{"UTC", "America/New_York", "America/Sao_Paulo", "Asia/Kolkata", "Pacific/Chatham"}
+	first := time.Date(2018, 10, 20, 5, 0, 0, 0, time.UTC)
+	last := time.Date(2019, 3, 1, 5, 0, 0, 0, time.UTC)
+
+	for _, name := range zones {
+		loc := mustLoad(t, name)
+		for _, g := range Granularities {
+			periods := PeriodRange(first, last, g, loc)
+			if len(periods) == 0 {
+				t.Fatalf("%s %s: no periods", name, g)
+			}
+			if start := periods[0]; first.Before(start.Start) || !first.Before(start.End) {
+				t.Errorf("%s %s: first period %s does not contain the first time", name, g, start.Key)
+			}
+			if end := periods[len(periods)-1]; last.Before(end.Start) || !last.Before(end.End) {
+				t.Errorf("%s %s: last period %s does not contain the last time", name, g, end.Key)
+			}
+			seen := make(map[string]bool)
+			for i, p := range periods {
+				if !p.Start.Before(p.End) {
+					t.Errorf("%s %s: period %s is empty", name, g, p.Key)
+				}
+				if i > 0 && !periods[i-1].End.Equal(p.Start) {
+					t.Errorf("%s %s: gap or overlap between %s and %s", name, g, periods[i-1].Key, p.Key)
+				}
+				if seen[p.Key] {
+					t.Errorf("%s %s: key %s repeats", name, g, p.Key)
+				}
+				seen[p.Key] = true
+			}
+		}
+	}
+}
+
+func TestPeriodRangeSkippedMidnight(t *testing.T) {
+	saoPaulo := mustLoad(t, "America/Sao_Paulo")
+	periods := PeriodRange(
+		time.Date(2018, 11, 3, 12, 0, 0, 0, saoPaulo),
+		time.Date(2018, 11, 5, 12, 0, 0, 0, saoPaulo),
+		GranularityDay, saoPaulo)
+
+	var keys []string
+	for _, p := range periods {
+		keys = append(keys, p.Key)
+	}
+	if strings.Join(keys, ",") != "2018-11-03,2018-11-04,2018-11-05" {
+		t.Errorf("days = %v, want 2018-11-03 to 2018-11-05", keys)
+	}
+}
+
+func TestUploadFrequencyGapFilled(t *testing.T) {
+	videos := []models.Video{
+		{ID: "c", PublishedAt: time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: "a", PublishedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)},
+		{ID: "b", PublishedAt: time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+	}
+
+	frequency := UploadFrequency(videos, GranularityMonth, time.UTC)
+	var got []string
+	for _, f := range frequency {
+		got = append(got, fmt.Sprintf("%s=%d", f.Period, f.Count))
+	}
+	if want := "2024-01=2,2024-02=0,2024-03=0,2024-04=1"; strings.Join(got, ",") != want {
+		t.Errorf("upload frequency = %v, want %s", got, want)
+	}
+
+	if len(UploadFrequency(nil, GranularityDay, time.UTC)) != 0 {
+		t.Errorf("upload frequency of no videos is not empty")
+	}
+}
+
+func TestCheckPeriods(t *testing.T) {
+	videos := []models.Video{
+		{ID: "a", PublishedAt: time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "b", PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	if err := CheckPeriods(videos, GranularityDay, time.UTC); err == nil {
+		t.Errorf("nine years of days passed the limit of %d periods", MaxPeriods)
+	}
+	if err := CheckPeriods(videos, GranularityWeek, time.UTC); err != nil {
+		t.Errorf("nine years of weeks: %v", err)
+	}
+	if err := CheckPeriods(nil, GranularityDay, time.UTC); err != nil {
+		t.Errorf("no videos: %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// PublishingWindowHours is the length of the recommended publishing window
+	PublishingWindowHours = 3
+	// minWindowUploads is the fewest uploads a window needs to be recommended
+	minWindowUploads = 3
+)
+
+// weekdays lists the days of the week Monday first, as the heatmap is laid out
+var weekdays = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+// slotIndex places a publish time in the 7x24 heatmap, Monday 00:00 being 0
+func slotIndex(t time.Time) int {
+	day := (int(t.Weekday()) + 6) % 7
+	return day*24 + t.Hour()
+}
+
+// ComputePublishing buckets uploads by the weekday and hour they were published
+// in loc. A video's performance is its views relative to its outlier baseline,
+// so a slot is not favoured just because the channel used it while it was bigger.
+//
+// The recommended window is the PublishingWindowHours consecutive hours, within one
+// weekday, whose uploads have the highest median multiple, among windows with at
+// least three uploads.
+func ComputePublishing(channel *models.Channel, videos []models.Video, loc *time.Location, now time.Time) *models.PublishingHeatmap {
+	views := make([][]float64, 7*24)
+	multiples := make([][]float64, 7*24)
+	for _, b := range Baselines(videos, DefaultOutlierWindow) {
+		i := slotIndex(b.Video.PublishedAt.In(loc))
+		views[i] = append(views[i], float64(b.Video.Views))
+		multiples[i] = append(multiples[i], b.Multiple)
+	}
+
+	slots := make([]models.PublishingSlot, 0, 7*24)
+	for d, day := range weekdays {
+		for hour := 0; hour < 24; hour++ {
+			i := d*24 + hour
+			slots = append(slots, models.PublishingSlot{
+				Weekday:        day.String(),
+				Hour:           hour,
+				Uploads:        len(views[i]),
+				MedianViews:    Median(views[i]),
+				MedianMultiple: Median(multiples[i]),
+			})
+		}
+	}
+
+	return &models.PublishingHeatmap{
+		ChannelID:    channel.ID,
+		ChannelTitle: channel.Title,
+		Timezone:     loc.String(),
+		TotalVideos:  len(videos),
+		Slots:        slots,
+		Recommended:  recommendWindow(multiples),
+		Timestamp:    now,
+	}
+}
+
+// recommendWindow finds the best publishing window in the heatmap, or nil if no
+// window has enough uploads
+func recommendWindow(multiples [][]float64) *models.PublishingWindow {
+	var best *models.PublishingWindow
+	for d, day := range weekdays {
+		for start := 0; start+PublishingWindowHours <= 24; start++ {
+			var pooled []float64
+			for hour := start; hour < start+PublishingWindowHours; hour++ {
+				pooled = append(pooled, multiples[d*24+hour]...)
+			}
+			if len(pooled) < minWindowUploads {
+				continue
+			}
+
+			median := Median(pooled)
+			if best == nil || median > best.MedianMultiple ||
+				(median == best.MedianMultiple && len(pooled) > best.Uploads) {
+				best = &models.PublishingWindow{
+					Weekday:        day.String(),
+					StartHour:      start,
+					EndHour:        start + PublishingWindowHours,
+					Uploads:        len(pooled),
+					MedianMultiple: median,
+				}
+			}
+		}
+	}
+	return best
+}
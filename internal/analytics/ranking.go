@@ -0,0 +1,58 @@
+package analytics
+
+import (
+	"sort"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// Rank returns a copy of the videos ordered by the scorer, highest first
+func Rank(videos []models.Video, scorer Scorer, ctx ScoreContext) []models.Video {
+	type scored struct {
+		video models.Video
+		score float64
+	}
+	ranked := make([]scored, len(videos))
+	for i, v := range videos {
+		ranked[i] = scored{video: v, score: scorer.Score(v, ctx)}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		if ranked[i].video.Views != ranked[j].video.Views {
+			return ranked[i].video.Views > ranked[j].video.Views
+		}
+		return ranked[i].video.ID < ranked[j].video.ID
+	})
+
+	result := make([]models.Video, len(ranked))
+	for i, r := range ranked {
+		result[i] = r.video
+	}
+	return result
+}
+
+// Top returns the n best videos according to the scorer
+func Top(videos []models.Video, n int, scorer Scorer, ctx ScoreContext) []models.Video {
+	ranked := Rank(videos, scorer, ctx)
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// Chronological returns a copy of the videos ordered by publish time, oldest first
+func Chronological(videos []models.Video) []models.Video {
+	ordered := make([]models.Video, len(videos))
+	copy(ordered, videos)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if !ordered[i].PublishedAt.Equal(ordered[j].PublishedAt) {
+			return ordered[i].PublishedAt.Before(ordered[j].PublishedAt)
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+	return ordered
+}
@@ -0,0 +1,167 @@
+package analytics
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// Scoring strategy names accepted by NewScorer and ?scoring=
+const (
+	ScoringWeighted   = "weighted"
+	ScoringRate       = "rate"
+	ScoringSubscriber = "subscriber"
+	ScoringAge        = "age"
+	ScoringExpected   = "expected"
+)
+
+// ScoringStrategies lists every strategy name in a stable order
+var ScoringStrategies = []string{ScoringWeighted, ScoringRate, ScoringSubscriber, ScoringAge, ScoringExpected}
+
+// DefaultWeights are the weights Video.EngagementScore has always used
+var DefaultWeights = models.ScoringWeights{Views: 1, Likes: 2, Comments: 3}
+
+// minRateViews keeps videos with a handful of views from topping per-view rankings
+const minRateViews = 100
+
+// ScoreContext is the channel-level information some strategies normalize by
+type ScoreContext struct {
+	Subscribers int64
+	Now         time.Time
+	// Curve is the channel's expected views by video age
+	Curve models.AgeCurve
+}
+
+// Scorer assigns each video an engagement score; higher is better
+type Scorer interface {
+	Model() models.ScoringModel
+	Score(v models.Video, ctx ScoreContext) float64
+}
+
+// DefaultScorer ranks by weighted absolute counts with the default weights
+var DefaultScorer Scorer = weightedScorer{weights: DefaultWeights}
+
+// NewScorer returns the named strategy using the given weights
+func NewScorer(name string, weights models.ScoringWeights) (Scorer, error) {
+	switch strings.ToLower(name) {
+	case ScoringWeighted:
+		return weightedScorer{weights: weights}, nil
+	case ScoringRate:
+		return rateScorer{weights: weights}, nil
+	case ScoringSubscriber:
+		return subscriberScorer{weights: weights}, nil
+	case ScoringAge:
+		return ageScorer{weights: weights}, nil
+	case ScoringExpected:
+		return expectedScorer{weights: weights}, nil
+	default:
+		return nil, fmt.Errorf("unknown scoring model %q (expected one of %s)", name, strings.Join(ScoringStrategies, ", "))
+	}
+}
+
+// weightedSum is the weighted total of a video's views, likes and comments
+func weightedSum(v models.Video, w models.ScoringWeights) float64 {
+	return w.Views*float64(v.Views) + w.Likes*float64(v.Likes) + w.Comments*float64(v.Comments)
+}
+
+// weightedScorer scores absolute counts, so bigger and older videos win
+type weightedScorer struct{ weights models.ScoringWeights }
+
+func (s weightedScorer) Model() models.ScoringModel {
+	return models.ScoringModel{
+		Name:        ScoringWeighted,
+		Description: "Weighted sum of views, likes and comments",
+		Weights:     s.weights,
+	}
+}
+
+func (s weightedScorer) Score(v models.Video, _ ScoreContext) float64 {
+	if v.Views == 0 {
+		return 0
+	}
+	return weightedSum(v, s.weights)
+}
+
+// rateScorer scores interaction per view, independent of reach
+type rateScorer struct{ weights models.ScoringWeights }
+
+func (s rateScorer) Model() models.ScoringModel {
+	return models.ScoringModel{
+		Name:        ScoringRate,
+		Description: "Weighted likes and comments per view; the view weight is unused and videos under 100 views score 0",
+		Weights:     s.weights,
+	}
+}
+
+func (s rateScorer) Score(v models.Video, _ ScoreContext) float64 {
+	if v.Views < minRateViews {
+		return 0
+	}
+	return (s.weights.Likes*float64(v.Likes) + s.weights.Comments*float64(v.Comments)) / float64(v.Views)
+}
+
+// subscriberScorer scores weighted counts per subscriber, so channels of any size compare
+type subscriberScorer struct{ weights models.ScoringWeights }
+
+func (s subscriberScorer) Model() models.ScoringModel {
+	return models.ScoringModel{
+		Name:        ScoringSubscriber,
+		Description: "Weighted sum of views, likes and comments per channel subscriber",
+		Weights:     s.weights,
+	}
+}
+
+func (s subscriberScorer) Score(v models.Video, ctx ScoreContext) float64 {
+	if v.Views == 0 {
+		return 0
+	}
+	// Hidden subscriber counts come back as zero; fall back to absolute counts
+	subscribers := float64(ctx.Subscribers)
+	if subscribers < 1 {
+		subscribers = 1
+	}
+	return weightedSum(v, s.weights) / subscribers
+}
+
+// ageScorer scores weighted counts per day since publishing, so new videos can compete
+type ageScorer struct{ weights models.ScoringWeights }
+
+func (s ageScorer) Model() models.ScoringModel {
+	return models.ScoringModel{
+		Name:        ScoringAge,
+		Description: "Weighted sum of views, likes and comments per day since publishing (at least one day)",
+		Weights:     s.weights,
+	}
+}
+
+func (s ageScorer) Score(v models.Video, ctx ScoreContext) float64 {
+	if v.Views == 0 {
+		return 0
+	}
+	days := ctx.Now.Sub(v.PublishedAt).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	return weightedSum(v, s.weights) / days
+}
+
+// expectedScorer scores views against what the channel's age curve expects of a video that old
+type expectedScorer struct{ weights models.ScoringWeights }
+
+func (s expectedScorer) Model() models.ScoringModel {
+	return models.ScoringModel{
+		Name:        ScoringExpected,
+		Description: "Views relative to the channel's expected views for the video's age; the weights are unused",
+		Weights:     s.weights,
+	}
+}
+
+func (s expectedScorer) Score(v models.Video, ctx ScoreContext) float64 {
+	expected := ExpectedViews(ctx.Curve, AgeDays(v, ctx.Now))
+	if expected <= 0 {
+		return 0
+	}
+	return float64(v.Views) / expected
+}
@@ -0,0 +1,95 @@
+package analytics
+
+import (
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// PerformanceOverTime returns one point per video, in the order given
+func PerformanceOverTime(videos []models.Video) []models.VideoPerformancePoint {
+	points := make([]models.VideoPerformancePoint, 0, len(videos))
+	for _, v := range videos {
+		likesToViews, commentsToViews := 0.0, 0.0
+		if v.Views > 0 {
+			likesToViews = float64(v.Likes) / float64(v.Views)
+			commentsToViews = float64(v.Comments) / float64(v.Views)
+		}
+		points = append(points, models.VideoPerformancePoint{
+			UploadDate:      v.PublishedAt,
+			Views:           v.Views,
+			Likes:           v.Likes,
+			Comments:        v.Comments,
+			LikesToViews:    likesToViews,
+			CommentsToViews: commentsToViews,
+		})
+	}
+	return points
+}
+
+// RollingAverages returns, for each video in the order given, the average views of
+// that video and up to window-1 videos before it
+func RollingAverages(videos []models.Video, window int) []models.RollingAverage {
+	if window < 1 {
+		window = 1
+	}
+
+	averages := make([]models.RollingAverage, 0, len(videos))
+	var sum int64
+	for i, v := range videos {
+		sum += v.Views
+		if i >= window {
+			sum -= videos[i-window].Views
+		}
+		count := i + 1
+		if count > window {
+			count = window
+		}
+		averages = append(averages, models.RollingAverage{
+			UploadIndex:  i,
+			AverageViews: float64(sum) / float64(count),
+		})
+	}
+	return averages
+}
+
+// UploadFrequency counts uploads per period, including periods without any
+func UploadFrequency(videos []models.Video, g Granularity, loc *time.Location) []models.UploadFrequency {
+	periods, groups := periodGroups(videos, g, loc)
+
+	frequency := make([]models.UploadFrequency, 0, len(periods))
+	for _, p := range periods {
+		frequency = append(frequency, models.UploadFrequency{
+			Period:      p.Key,
+			PeriodStart: p.Start,
+			PeriodEnd:   p.End,
+			Count:       len(groups[p.Key]),
+		})
+	}
+	return frequency
+}
+
+// EngagementTrends averages views, likes and comments of the uploads in each period.
+// Periods without uploads are included with zero averages.
+func EngagementTrends(videos []models.Video, g Granularity, loc *time.Location) []models.EngagementTrend {
+	periods, groups := periodGroups(videos, g, loc)
+
+	trends := make([]models.EngagementTrend, 0, len(periods))
+	for _, p := range periods {
+		trend := models.EngagementTrend{
+			Period:      p.Key,
+			PeriodStart: p.Start,
+			PeriodEnd:   p.End,
+		}
+		if group := groups[p.Key]; len(group) > 0 {
+			views, likes, comments := Totals(group)
+			count := float64(len(group))
+			trend.Uploads = len(group)
+			trend.AverageViews = float64(views) / count
+			trend.AverageLikes = float64(likes) / count
+			trend.AverageComments = float64(comments) / count
+		}
+		trends = append(trends, trend)
+	}
+	return trends
+}
@@ -0,0 +1,262 @@
+package analytics
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// DefaultMinTagVideos is the fewest videos a tag needs to be ranked by performance
+	DefaultMinTagVideos = 3
+	// DefaultTagLimit is how many tags each ranking lists
+	DefaultTagLimit = 25
+
+	// tagUsageCount is how many of the most frequent tags get a usage series
+	tagUsageCount = 10
+	// aboveBaselineMultiple is the median multiple a tag's videos must reach to count as above baseline
+	aboveBaselineMultiple = 1.2
+	// maxSharedTags bounds the shared tags listed by a comparison
+	maxSharedTags = 100
+)
+
+// hashtagPattern matches a #hashtag; ones made only of digits, such as #1, are dropped
+var hashtagPattern = regexp.MustCompile(`#[\p{L}\p{N}_]+`)
+
+// Hashtags returns the distinct lower case hashtags of a text, in order of first use
+func Hashtags(text string) []string {
+	var hashtags []string
+	seen := make(map[string]bool)
+	for _, match := range hashtagPattern.FindAllString(text, -1) {
+		tag := strings.ToLower(match)
+		if strings.Trim(tag, "#0123456789") == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		hashtags = append(hashtags, tag)
+	}
+	return hashtags
+}
+
+// VideoTags returns a video's distinct lower case tags followed by the hashtags in
+// its title and description. Hashtags keep their "#" so they never merge with tags.
+func VideoTags(v models.Video) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, tag := range v.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	for _, tag := range Hashtags(v.Title + "\n" + v.Description) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// tagKind tells hashtags from uploader tags
+func tagKind(tag string) models.TagKind {
+	if strings.HasPrefix(tag, "#") {
+		return models.TagKindHashtag
+	}
+	return models.TagKindTag
+}
+
+// ComputeTags ranks a channel's tags and hashtags by how often they are used and by
+// how the videos using them performed against their outlier baselines. Rankings by
+// performance only include tags on at least minVideos videos, and every ranking
+// lists at most limit tags.
+func ComputeTags(channel *models.Channel, videos []models.Video, minVideos, limit int, now time.Time) *models.ChannelTags {
+	type tagVideos struct {
+		stat      models.TagStat
+		views     []float64
+		multiples []float64
+		videos    []models.Video
+	}
+	byTag := make(map[string]*tagVideos)
+	tagged := 0
+	for _, b := range Baselines(videos, DefaultOutlierWindow) {
+		tags := VideoTags(b.Video)
+		if len(tags) > 0 {
+			tagged++
+		}
+		for _, tag := range tags {
+			t := byTag[tag]
+			if t == nil {
+				t = &tagVideos{stat: models.TagStat{Tag: tag, Kind: tagKind(tag), FirstUsed: b.Video.PublishedAt}}
+				byTag[tag] = t
+			}
+			// Baselines are oldest first, so the last video seen is the latest use
+			t.stat.LastUsed = b.Video.PublishedAt
+			t.views = append(t.views, float64(b.Video.Views))
+			t.multiples = append(t.multiples, b.Multiple)
+			t.videos = append(t.videos, b.Video)
+		}
+	}
+
+	stats := make([]models.TagStat, 0, len(byTag))
+	for _, t := range byTag {
+		above := 0
+		for _, m := range t.multiples {
+			if m > 1 {
+				above++
+			}
+		}
+		t.stat.Videos = len(t.views)
+		t.stat.MedianViews = Median(t.views)
+		t.stat.MedianMultiple = Median(t.multiples)
+		t.stat.AboveBaselineShare = float64(above) / float64(len(t.multiples))
+		stats = append(stats, t.stat)
+	}
+
+	byFrequency := make([]models.TagStat, len(stats))
+	copy(byFrequency, stats)
+	sort.Slice(byFrequency, func(i, j int) bool {
+		if byFrequency[i].Videos != byFrequency[j].Videos {
+			return byFrequency[i].Videos > byFrequency[j].Videos
+		}
+		return byFrequency[i].Tag < byFrequency[j].Tag
+	})
+
+	byPerformance := []models.TagStat{}
+	for _, s := range stats {
+		if s.Videos >= minVideos {
+			byPerformance = append(byPerformance, s)
+		}
+	}
+	sort.Slice(byPerformance, func(i, j int) bool {
+		if byPerformance[i].MedianMultiple != byPerformance[j].MedianMultiple {
+			return byPerformance[i].MedianMultiple > byPerformance[j].MedianMultiple
+		}
+		return byPerformance[i].Tag < byPerformance[j].Tag
+	})
+
+	aboveBaseline := []models.TagStat{}
+	for _, s := range byPerformance {
+		if s.MedianMultiple >= aboveBaselineMultiple && s.AboveBaselineShare > 0.5 {
+			aboveBaseline = append(aboveBaseline, s)
+		}
+	}
+
+	usage := []models.TagUsage{}
+	if first, last, ok := publishedBounds(videos); ok {
+		months := PeriodRange(first, last, GranularityMonth, time.UTC)
+		for i := 0; i < len(byFrequency) && i < tagUsageCount; i++ {
+			tag := byFrequency[i].Tag
+			groups := groupByPeriod(byTag[tag].videos, GranularityMonth, time.UTC)
+			monthly := make([]models.UploadFrequency, 0, len(months))
+			for _, month := range months {
+				monthly = append(monthly, models.UploadFrequency{
+					Period:      month.Key,
+					PeriodStart: month.Start,
+					PeriodEnd:   month.End,
+					Count:       len(groups[month.Key]),
+				})
+			}
+			usage = append(usage, models.TagUsage{Tag: tag, Monthly: monthly})
+		}
+	}
+
+	return &models.ChannelTags{
+		ChannelID:     channel.ID,
+		ChannelTitle:  channel.Title,
+		TotalVideos:   len(videos),
+		TaggedVideos:  tagged,
+		MinVideos:     minVideos,
+		ByFrequency:   limitTags(byFrequency, limit),
+		ByPerformance: limitTags(byPerformance, limit),
+		AboveBaseline: limitTags(aboveBaseline, limit),
+		UsageOverTime: usage,
+		Timestamp:     now,
+	}
+}
+
+// limitTags returns at most limit tags
+func limitTags(stats []models.TagStat, limit int) []models.TagStat {
+	if len(stats) > limit {
+		return stats[:limit]
+	}
+	return stats
+}
+
+// CompareTags finds the tags several channels have in common; videos[i] holds the
+// uploads of channels[i]. Shared tags are ordered by how many channels use them,
+// then by how many videos, and overlaps by Jaccard similarity.
+func CompareTags(channels []*models.Channel, videos [][]models.Video, now time.Time) *models.TagComparison {
+	comparison := &models.TagComparison{
+		Channels:   make([]models.TagChannel, len(channels)),
+		SharedTags: []models.SharedTag{},
+		Overlaps:   []models.TagOverlap{},
+		Timestamp:  now,
+	}
+
+	sets := make([]map[string]int, len(channels))
+	shared := make(map[string]*models.SharedTag)
+	for i, channel := range channels {
+		sets[i] = make(map[string]int)
+		for _, v := range videos[i] {
+			for _, tag := range VideoTags(v) {
+				sets[i][tag]++
+			}
+		}
+		comparison.Channels[i] = models.TagChannel{ChannelID: channel.ID, ChannelTitle: channel.Title, Tags: len(sets[i])}
+
+		for tag, count := range sets[i] {
+			s := shared[tag]
+			if s == nil {
+				s = &models.SharedTag{Tag: tag, Kind: tagKind(tag)}
+				shared[tag] = s
+			}
+			s.ChannelIDs = append(s.ChannelIDs, channel.ID)
+			s.Videos += count
+		}
+	}
+
+	for _, s := range shared {
+		if len(s.ChannelIDs) > 1 {
+			comparison.SharedTags = append(comparison.SharedTags, *s)
+		}
+	}
+	sort.Slice(comparison.SharedTags, func(i, j int) bool {
+		a, b := comparison.SharedTags[i], comparison.SharedTags[j]
+		if len(a.ChannelIDs) != len(b.ChannelIDs) {
+			return len(a.ChannelIDs) > len(b.ChannelIDs)
+		}
+		if a.Videos != b.Videos {
+			return a.Videos > b.Videos
+		}
+		return a.Tag < b.Tag
+	})
+	if len(comparison.SharedTags) > maxSharedTags {
+		comparison.SharedTags = comparison.SharedTags[:maxSharedTags]
+	}
+
+	for i := range channels {
+		for j := i + 1; j < len(channels); j++ {
+			common := 0
+			for tag := range sets[i] {
+				if _, ok := sets[j][tag]; ok {
+					common++
+				}
+			}
+			overlap := models.TagOverlap{ChannelA: channels[i].ID, ChannelB: channels[j].ID, SharedTags: common}
+			if union := len(sets[i]) + len(sets[j]) - common; union > 0 {
+				overlap.Jaccard = float64(common) / float64(union)
+			}
+			comparison.Overlaps = append(comparison.Overlaps, overlap)
+		}
+	}
+	sort.SliceStable(comparison.Overlaps, func(i, j int) bool {
+		return comparison.Overlaps[i].Jaccard > comparison.Overlaps[j].Jaccard
+	})
+
+	return comparison
+}
@@ -0,0 +1,226 @@
+package analytics
+
+import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	// DefaultMinTermVideos is the fewest titles a term must appear in to be listed
+	DefaultMinTermVideos = 3
+	// DefaultTermLimit is how many terms of each length are listed
+	DefaultTermLimit = 25
+)
+
+// stopWords are left out of unigrams and may not start or end a longer phrase
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "how": true, "i": true, "in": true,
+	"is": true, "it": true, "my": true, "of": true, "on": true, "or": true, "so": true,
+	"that": true, "the": true, "this": true, "to": true, "was": true, "we": true,
+	"what": true, "with": true, "you": true, "your": true,
+}
+
+// Tokenize splits a title into lower case words, dropping punctuation and emoji
+func Tokenize(title string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+
+	// Keep apostrophes inside words such as "don't" but not quotes around them
+	tokens := fields[:0]
+	for _, field := range fields {
+		if token := strings.Trim(field, "'"); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// titleTerms returns the distinct n-grams of a title for n from 1 to 3, indexed by n-1
+func titleTerms(title string) [3]map[string]bool {
+	var terms [3]map[string]bool
+	tokens := Tokenize(title)
+	for n := 1; n <= 3; n++ {
+		terms[n-1] = make(map[string]bool)
+		for i := 0; i+n <= len(tokens); i++ {
+			if stopWords[tokens[i]] || stopWords[tokens[i+n-1]] {
+				continue
+			}
+			terms[n-1][strings.Join(tokens[i:i+n], " ")] = true
+		}
+	}
+	return terms
+}
+
+// ComputeTitles finds the words and phrases a channel's titles use most and how
+// the videos using them performed, and relates structural features of titles to views.
+// Terms in fewer than minVideos titles are left out and at most limit terms of each
+// length are listed.
+func ComputeTitles(channel *models.Channel, videos []models.Video, minVideos, limit int, now time.Time) *models.ChannelTitles {
+	baselines := Baselines(videos, DefaultOutlierWindow)
+
+	type termVideos struct {
+		views, multiples []float64
+	}
+	var grams [3]map[string]*termVideos
+	for n := range grams {
+		grams[n] = make(map[string]*termVideos)
+	}
+	for _, b := range baselines {
+		terms := titleTerms(b.Video.Title)
+		for n := range terms {
+			for term := range terms[n] {
+				t := grams[n][term]
+				if t == nil {
+					t = &termVideos{}
+					grams[n][term] = t
+				}
+				t.views = append(t.views, float64(b.Video.Views))
+				t.multiples = append(t.multiples, b.Multiple)
+			}
+		}
+	}
+
+	var lists [3][]models.TitleTerm
+	for n := range grams {
+		list := []models.TitleTerm{}
+		for term, t := range grams[n] {
+			if len(t.views) < minVideos {
+				continue
+			}
+			list = append(list, models.TitleTerm{
+				Term:           term,
+				Videos:         len(t.views),
+				MedianViews:    Median(t.views),
+				MedianMultiple: Median(t.multiples),
+			})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Videos != list[j].Videos {
+				return list[i].Videos > list[j].Videos
+			}
+			if list[i].MedianMultiple != list[j].MedianMultiple {
+				return list[i].MedianMultiple > list[j].MedianMultiple
+			}
+			return list[i].Term < list[j].Term
+		})
+		if len(list) > limit {
+			list = list[:limit]
+		}
+		lists[n] = list
+	}
+
+	binary, numeric := titleFeatures(videos)
+	return &models.ChannelTitles{
+		ChannelID:       channel.ID,
+		ChannelTitle:    channel.Title,
+		TotalVideos:     len(videos),
+		MinVideos:       minVideos,
+		Unigrams:        lists[0],
+		Bigrams:         lists[1],
+		Trigrams:        lists[2],
+		BinaryFeatures:  binary,
+		NumericFeatures: numeric,
+		Timestamp:       now,
+	}
+}
+
+// binaryTitleFeatures are the yes-or-no properties of a title, in the order reported
+var binaryTitleFeatures = []struct {
+	name string
+	has  func(title string) bool
+}{
+	{"hasNumber", func(title string) bool { return strings.IndexFunc(title, unicode.IsDigit) >= 0 }},
+	{"isQuestion", func(title string) bool { return strings.ContainsAny(title, "?？") }},
+	{"hasAllCapsWord", hasAllCapsWord},
+	{"hasEmoji", func(title string) bool { return strings.IndexFunc(title, isEmoji) >= 0 }},
+	{"hasBrackets", func(title string) bool { return strings.ContainsAny(title, "()[]{}【】「」") }},
+}
+
+// numericTitleFeatures are the measured properties of a title, in the order reported
+var numericTitleFeatures = []struct {
+	name    string
+	measure func(title string) float64
+}{
+	{"characters", func(title string) float64 { return float64(utf8.RuneCountInString(title)) }},
+	{"words", func(title string) float64 { return float64(len(strings.Fields(title))) }},
+}
+
+// titleFeatures relates each structural feature of the titles to views
+func titleFeatures(videos []models.Video) ([]models.TitleBinaryFeature, []models.TitleNumericFeature) {
+	views := make([]float64, len(videos))
+	for i, v := range videos {
+		views[i] = float64(v.Views)
+	}
+
+	binary := make([]models.TitleBinaryFeature, 0, len(binaryTitleFeatures))
+	for _, feature := range binaryTitleFeatures {
+		indicator := make([]float64, len(videos))
+		var with, without []float64
+		for i, v := range videos {
+			if feature.has(v.Title) {
+				indicator[i] = 1
+				with = append(with, views[i])
+			} else {
+				without = append(without, views[i])
+			}
+		}
+		binary = append(binary, models.TitleBinaryFeature{
+			Feature:            feature.name,
+			VideosWith:         len(with),
+			MedianViewsWith:    Median(with),
+			MedianViewsWithout: Median(without),
+			Correlation:        SpearmanCorrelation(indicator, views),
+		})
+	}
+
+	numeric := make([]models.TitleNumericFeature, 0, len(numericTitleFeatures))
+	for _, feature := range numericTitleFeatures {
+		values := make([]float64, len(videos))
+		var sum float64
+		for i, v := range videos {
+			values[i] = feature.measure(v.Title)
+			sum += values[i]
+		}
+		result := models.TitleNumericFeature{
+			Feature:     feature.name,
+			Median:      Median(values),
+			Correlation: SpearmanCorrelation(values, views),
+		}
+		if len(values) > 0 {
+			result.Mean = sum / float64(len(values))
+		}
+		numeric = append(numeric, result)
+	}
+	return binary, numeric
+}
+
+// hasAllCapsWord reports whether a title has a word of two or more letters, all upper case
+func hasAllCapsWord(title string) bool {
+	for _, word := range strings.Fields(title) {
+		letters, upper := 0, 0
+		for _, r := range word {
+			if unicode.IsLetter(r) {
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			}
+		}
+		if letters >= 2 && upper == letters {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmoji reports whether r is a pictographic symbol rather than punctuation or a letter
+func isEmoji(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x2B00 && r <= 0x2BFF)
+}
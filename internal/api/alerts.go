@@ -0,0 +1,262 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/alerts"
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 500
+)
+
+// AlertsHandler manages alert rules on tracked channels and their webhook deliveries
+type AlertsHandler struct {
+	db     *models.Database
+	engine *alerts.Engine
+}
+
+// NewAlertsHandler creates a new alerts handler
+func NewAlertsHandler(db *models.Database, engine *alerts.Engine) *AlertsHandler {
+	return &AlertsHandler{db: db, engine: engine}
+}
+
+// CreateAlert adds a rule. The signing secret is generated if none is given and is only
+// returned by this call and by updates.
+func (a *AlertsHandler) CreateAlert(c *gin.Context) {
+	rule, ok := a.bindRule(c)
+	if !ok {
+		return
+	}
+
+	if err := a.db.CreateAlertRule(rule); err != nil {
+		log.Printf("Error creating alert rule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := a.db.GetAlertRule(rule.ID)
+	if err != nil || created == nil {
+		c.JSON(http.StatusCreated, rule)
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+// GetAlerts lists rules, optionally only those of ?channel=
+func (a *AlertsHandler) GetAlerts(c *gin.Context) {
+	rules, err := a.db.GetAlertRules(c.Query("channel"), false)
+	if err != nil {
+		log.Printf("Error fetching alert rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, rule := range rules {
+		rule.Secret = ""
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetAlert returns a single rule
+func (a *AlertsHandler) GetAlert(c *gin.Context) {
+	rule, ok := a.loadRule(c)
+	if !ok {
+		return
+	}
+	rule.Secret = ""
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateAlert replaces a rule's definition; its evaluation state starts over
+func (a *AlertsHandler) UpdateAlert(c *gin.Context) {
+	existing, ok := a.loadRule(c)
+	if !ok {
+		return
+	}
+
+	rule, ok := a.bindRule(c)
+	if !ok {
+		return
+	}
+	rule.ID = existing.ID
+	if rule.Secret == "" {
+		rule.Secret = existing.Secret
+	}
+
+	if err := a.db.UpdateAlertRule(rule); err != nil {
+		log.Printf("Error updating alert rule %d: %v", rule.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := a.db.GetAlertRule(rule.ID)
+	if err != nil || updated == nil {
+		c.JSON(http.StatusOK, rule)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteAlert removes a rule and its delivery log
+func (a *AlertsHandler) DeleteAlert(c *gin.Context) {
+	id, ok := parseAlertID(c)
+	if !ok {
+		return
+	}
+
+	removed, err := a.db.DeleteAlertRule(id)
+	if err != nil {
+		log.Printf("Error deleting alert rule %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// TestAlert sends a sample event to the rule's webhook and returns the delivery
+func (a *AlertsHandler) TestAlert(c *gin.Context) {
+	rule, ok := a.loadRule(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := a.engine.TestFire(rule)
+	if err != nil {
+		log.Printf("Error test-firing alert rule %d: %v", rule.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// GetAlertDeliveries returns a page of a rule's delivery log, newest first
+func (a *AlertsHandler) GetAlertDeliveries(c *gin.Context) {
+	rule, ok := a.loadRule(c)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		if n > maxDeliveriesLimit {
+			n = maxDeliveriesLimit
+		}
+		limit = n
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+		offset = n
+	}
+
+	deliveries, err := a.db.GetDeliveries(rule.ID, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching deliveries of alert rule %d: %v", rule.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// bindRule parses and validates a rule from the request body, writing the error response on failure
+func (a *AlertsHandler) bindRule(c *gin.Context) (*models.AlertRule, bool) {
+	var req models.AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return nil, false
+	}
+
+	webhook, err := url.Parse(req.WebhookURL)
+	if err != nil || (webhook.Scheme != "http" && webhook.Scheme != "https") || webhook.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "webhookUrl must be an absolute http or https URL"})
+		return nil, false
+	}
+
+	// Alerts are evaluated from stored data, which is only kept fresh for tracked channels
+	entry, err := a.db.GetWatchlistEntry(req.ChannelID)
+	if err != nil {
+		log.Printf("Error reading watchlist entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if entry == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel must be on the watchlist before alerts can be defined"})
+		return nil, false
+	}
+
+	rule := &models.AlertRule{
+		ChannelID:   req.ChannelID,
+		Type:        req.Type,
+		Threshold:   req.Threshold,
+		WindowHours: req.WindowHours,
+		WebhookURL:  req.WebhookURL,
+		Secret:      req.Secret,
+		Enabled:     req.Enabled == nil || *req.Enabled,
+	}
+	alerts.ApplyDefaults(rule)
+	if err := alerts.Validate(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if rule.Secret == "" && c.Request.Method == http.MethodPost {
+		secret, err := alerts.GenerateSecret()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return nil, false
+		}
+		rule.Secret = secret
+	}
+	return rule, true
+}
+
+// loadRule fetches the rule named by the :id parameter, writing the error response on failure
+func (a *AlertsHandler) loadRule(c *gin.Context) (*models.AlertRule, bool) {
+	id, ok := parseAlertID(c)
+	if !ok {
+		return nil, false
+	}
+
+	rule, err := a.db.GetAlertRule(id)
+	if err != nil {
+		log.Printf("Error fetching alert rule %d: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if rule == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Alert rule not found"})
+		return nil, false
+	}
+	return rule, true
+}
+
+func parseAlertID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Alert ID must be a positive integer"})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/models"
+)
+
+// maxConcurrentAnalyticsJobs bounds how many async crawls run at once to protect the API quota
+const maxConcurrentAnalyticsJobs = 2
+
+// CreateAnalyticsJob queues an asynchronous analytics computation and returns 202 with the job.
+// A channel that already has a queued or running job gets that job back instead of a new one.
+func (h *YouTubeAPI) CreateAnalyticsJob(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	jobID, err := newJobID()
+	if err != nil {
+		log.Printf("Error generating job ID: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, created, err := h.db.CreateAnalyticsJob(jobID, channelID)
+	if err != nil {
+		log.Printf("Error creating analytics job: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if created {
+		log.Printf("Queued analytics job %s for channel %s", jobID, channelID)
+		go h.runAnalyticsJob(jobID, channelID)
+	} else {
+		log.Printf("Channel %s already has analytics job %s", channelID, job.ID)
+	}
+
+	c.Header("Location", "/jobs/"+job.ID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ResumeAnalyticsJobs restarts jobs that were queued or running when the server stopped
+func (h *YouTubeAPI) ResumeAnalyticsJobs() {
+	jobs, err := h.db.GetUnfinishedAnalyticsJobs()
+	if err != nil {
+		log.Printf("Failed to load unfinished analytics jobs: %v", err)
+		return
+	}
+
+	for _, job := range jobs {
+		log.Printf("Resuming analytics job %s for channel %s", job.ID, job.ChannelID)
+		go h.runAnalyticsJob(job.ID, job.ChannelID)
+	}
+}
+
+// runAnalyticsJob waits for a free slot, computes the analytics and stores the outcome
+func (h *YouTubeAPI) runAnalyticsJob(jobID, channelID string) {
+	h.jobSlots <- struct{}{}
+	defer func() { <-h.jobSlots }()
+
+	if err := h.db.StartAnalyticsJob(jobID); err != nil {
+		log.Printf("Failed to mark analytics job %s as running: %v", jobID, err)
+	}
+
+	var data []byte
+	analytics, err := h.computeAnalyticsJob(jobID, channelID)
+	if err == nil {
+		data, err = json.Marshal(analytics)
+	}
+	if err != nil {
+		log.Printf("Analytics job %s for channel %s failed: %v", jobID, channelID, err)
+	}
+
+	if err := h.db.FinishAnalyticsJob(jobID, data, err); err != nil {
+		log.Printf("Failed to store outcome of analytics job %s: %v", jobID, err)
+	}
+}
+
+// computeAnalyticsJob crawls the channel while recording progress, then computes the
+// analytics from the freshly stored videos
+func (h *YouTubeAPI) computeAnalyticsJob(jobID, channelID string) (*models.ChannelAnalytics, error) {
+	// A crawl already running for this channel is joined, though it reports no progress
+	_, err, _ := h.inflight.Do(flightKey("crawl", channelID), func() (interface{}, error) {
+		return h.crawlVideos(channelID, func(p crawlProgress) {
+			if err := h.db.UpdateAnalyticsJobProgress(jobID, p.VideosFetched, p.VideosTotal); err != nil {
+				log.Printf("Failed to record progress of analytics job %s: %v", jobID, err)
+			}
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to crawl channel: %v", err)
+	}
+
+	return h.refreshAnalytics(channelID, false)
+}
+
+// newJobID returns a random 128-bit hex identifier
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
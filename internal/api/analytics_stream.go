@@ -0,0 +1,188 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/models"
+)
+
+// streamEvent is one Server-Sent Event waiting to be written to the client
+type streamEvent struct {
+	name string
+	data interface{}
+}
+
+// streamProgress is the payload of a "progress" event
+type streamProgress struct {
+	VideosFetched int64   `json:"videosFetched"`
+	VideosTotal   int64   `json:"videosTotal"`
+	BatchSize     int     `json:"batchSize"`
+	Percent       float64 `json:"percent"`
+}
+
+// streamAggregate is the payload of an "aggregate" event: running totals over
+// the videos fetched so far
+type streamAggregate struct {
+	Videos             int     `json:"videos"`
+	TotalViews         int64   `json:"totalViews"`
+	TotalLikes         int64   `json:"totalLikes"`
+	TotalComments      int64   `json:"totalComments"`
+	AverageViews       float64 `json:"averageViews"`
+	LikeToViewRatio    float64 `json:"likeToViewRatio"`
+	CommentToViewRatio float64 `json:"commentToViewRatio"`
+}
+
+// add folds a batch of videos into the running totals
+func (a *streamAggregate) add(videos []models.Video) {
+	for _, v := range videos {
+		a.Videos++
+		a.TotalViews += v.Views
+		a.TotalLikes += v.Likes
+		a.TotalComments += v.Comments
+	}
+	if a.Videos > 0 {
+		a.AverageViews = float64(a.TotalViews) / float64(a.Videos)
+	}
+	if a.TotalViews > 0 {
+		a.LikeToViewRatio = float64(a.TotalLikes) / float64(a.TotalViews)
+		a.CommentToViewRatio = float64(a.TotalComments) / float64(a.TotalViews)
+	}
+}
+
+// StreamChannelAnalytics computes a channel's analytics while streaming Server-Sent Events:
+// "channel" once the channel is found, "progress" and "aggregate" after each batch of videos,
+// then "result" with the final analytics or "error" if anything fails.
+// Recently stored data is streamed without a crawl unless ?refresh=true is given.
+func (h *YouTubeAPI) StreamChannelAnalytics(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	events := make(chan streamEvent, 16)
+	send := func(name string, data interface{}) {
+		select {
+		case events <- streamEvent{name: name, data: data}:
+		case <-ctx.Done():
+		}
+	}
+
+	// The work carries on if the client goes away so a finished crawl is still stored
+	go func() {
+		defer close(events)
+
+		analytics, err := h.streamAnalytics(channelID, refresh, send)
+		if err != nil {
+			log.Printf("Error streaming analytics for channel %s: %v", channelID, err)
+			send("error", gin.H{"error": err.Error()})
+			return
+		}
+		send("result", analytics)
+	}()
+
+	// Stop proxies such as nginx from buffering the stream
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.name, event.data)
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// streamAnalytics brings the stored channel up to date, reporting each step through send,
+// and returns the recomputed analytics
+func (h *YouTubeAPI) streamAnalytics(channelID string, refresh bool, send func(string, interface{})) (*models.ChannelAnalytics, error) {
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	stored, err := h.db.GetChannel(channelID)
+	if err != nil {
+		log.Printf("Error reading stored channel %s: %v", channelID, err)
+	}
+
+	if refresh || stored == nil || !stored.VideosFresh(policy.TTL) {
+		var aggregate streamAggregate
+		channelSent := false
+		_, err, shared := h.inflight.Do(flightKey("crawl", channelID), func() (interface{}, error) {
+			return h.crawlVideos(channelID, func(p crawlProgress) {
+				if !channelSent && p.Channel != nil {
+					send("channel", channelFromAPI(p.Channel))
+					channelSent = true
+				}
+				if len(p.Batch) == 0 {
+					return
+				}
+
+				batch := make([]models.Video, 0, len(p.Batch))
+				for _, v := range p.Batch {
+					if v == nil || v.Snippet == nil || v.Statistics == nil || v.ContentDetails == nil {
+						continue
+					}
+					batch = append(batch, videoFromAPI(v))
+				}
+				aggregate.add(batch)
+
+				send("progress", newStreamProgress(p.VideosFetched, p.VideosTotal, len(p.Batch)))
+				send("aggregate", aggregate)
+			})
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to crawl channel: %v", err)
+		}
+		if !shared {
+			return h.refreshAnalytics(channelID, false)
+		}
+		// Another request ran the crawl, so report what it stored in one go
+		if stored, err = h.db.GetChannel(channelID); err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			return nil, fmt.Errorf("channel not found")
+		}
+	}
+
+	videos, err := h.db.GetVideosByChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	var aggregate streamAggregate
+	aggregate.add(videos)
+	send("channel", stored)
+	send("progress", newStreamProgress(int64(len(videos)), int64(len(videos)), len(videos)))
+	send("aggregate", aggregate)
+
+	return h.refreshAnalytics(channelID, false)
+}
+
+// newStreamProgress builds a progress payload, capping the percentage at 100
+func newStreamProgress(fetched, total int64, batchSize int) streamProgress {
+	progress := streamProgress{VideosFetched: fetched, VideosTotal: total, BatchSize: batchSize}
+	if total > 0 {
+		progress.Percent = float64(fetched) / float64(total) * 100
+		if progress.Percent > 100 {
+			progress.Percent = 100
+		}
+	}
+	return progress
+}
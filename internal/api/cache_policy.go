@@ -0,0 +1,62 @@
+package api
+
+import (
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// CachePolicy controls how long a cached engagement result may be served
+type CachePolicy struct {
+	// TTL is how long a result counts as fresh
+	TTL time.Duration
+	// StaleWhileRevalidate is how long after expiry a stale result is still
+	// served while a background refresh replaces it
+	StaleWhileRevalidate time.Duration
+}
+
+// cacheState classifies a cached result against a policy
+type cacheState int
+
+const (
+	cacheFresh cacheState = iota
+	cacheStale
+	cacheExpired
+)
+
+// state reports whether a result fetched at fetchedAt is fresh, stale or expired
+func (p CachePolicy) state(fetchedAt, now time.Time) cacheState {
+	age := now.Sub(fetchedAt)
+	switch {
+	case age < p.TTL:
+		return cacheFresh
+	case age < p.TTL+p.StaleWhileRevalidate:
+		return cacheStale
+	default:
+		return cacheExpired
+	}
+}
+
+// metadata builds the cache metadata returned alongside a result
+func (p CachePolicy) metadata(fetchedAt time.Time, fromCache bool) models.CacheMetadata {
+	return models.CacheMetadata{
+		FetchedAt: fetchedAt,
+		FromCache: fromCache,
+		ExpiresAt: fetchedAt.Add(p.TTL),
+		Stale:     fromCache && p.state(fetchedAt, time.Now()) != cacheFresh,
+	}
+}
+
+// dataFetchedAt returns when the data behind a stored result was fetched from YouTube.
+// Results stored before this was recorded fall back to the time they were stored.
+func dataFetchedAt(stored models.CacheMetadata, storedAt time.Time) time.Time {
+	if stored.FetchedAt.IsZero() || stored.FetchedAt.After(storedAt) {
+		return storedAt
+	}
+	return stored.FetchedAt
+}
+
+// cacheControl builds the Cache-Control header for a result fetched at fetchedAt
+func (p CachePolicy) cacheControl(fetchedAt time.Time) string {
+	return maxAgeCacheControl(p.TTL-time.Since(fetchedAt), p.StaleWhileRevalidate)
+}
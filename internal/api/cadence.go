@@ -0,0 +1,42 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// GetChannelCadence reports how regularly a channel uploads: the gaps between
+// uploads, weekly streaks, a consistency score, schedule changes and when the
+// next upload is due
+func (h *YouTubeAPI) GetChannelCadence(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Computing upload cadence for channel: %s", channelID)
+	policy := h.cachePolicies[models.EngagementTypeTrends]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for cadence: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	cadence := analytics.ComputeCadence(channel, videos, time.Now())
+	cadence.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, cadence, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
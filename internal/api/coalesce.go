@@ -0,0 +1,72 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// flightCall is a fetch in progress that other callers can wait on
+type flightCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// flightGroup coalesces concurrent calls that share a key so that only one of
+// them does the work and the rest wait for its result
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// Do runs fn once per key at a time. Callers that arrive while a call for the
+// same key is in flight block until it finishes and receive the same result.
+// shared is true for callers that waited on another caller's call and false for
+// the caller that ran fn. A panic in fn is logged and returned to every caller as an error.
+func (g *flightGroup) Do(key string, fn func() (interface{}, error)) (val interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err, true
+	}
+
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	call.val, call.err = run(key, fn)
+	return call.val, call.err, false
+}
+
+// run calls fn, turning a panic into an error so waiting callers never see a nil result
+func run(key string, fn func() (interface{}, error)) (val interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Coalesced call %s panicked: %v\n%s", key, r, debug.Stack())
+			val, err = nil, fmt.Errorf("internal error while processing %s", key)
+		}
+	}()
+	return fn()
+}
+
+// InFlight reports whether a call for key is currently running
+func (g *flightGroup) InFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.calls[key]
+	return ok
+}
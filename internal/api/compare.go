@@ -0,0 +1,124 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// maxCompareChannels bounds how many channels one comparison covers
+const maxCompareChannels = 10
+
+// CompareChannels puts the metrics of several channels side by side.
+// ?ids= takes a comma separated list of channel IDs, @handles or channel URLs;
+// percent differences are measured from the first one. Channel statistics come from
+// a single batched channels.list call, and uploads are re-crawled only for channels
+// whose stored copy is outdated or with ?refresh=true.
+func (h *YouTubeAPI) CompareChannels(c *gin.Context) {
+	var refs []string
+	for _, param := range c.QueryArray("ids") {
+		for _, ref := range strings.Split(param, ",") {
+			if ref = strings.TrimSpace(ref); ref != "" {
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	channelIDs := make([]string, 0, len(refs))
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		channelID, err := h.resolveChannelRef(ref)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid channel %q: %v", ref, err)})
+			return
+		}
+		if !seen[channelID] {
+			seen[channelID] = true
+			channelIDs = append(channelIDs, channelID)
+		}
+	}
+	if len(channelIDs) < 2 || len(channelIDs) > maxCompareChannels {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ids must list between 2 and %d different channels", maxCompareChannels)})
+		return
+	}
+
+	log.Printf("Comparing channels: %s", strings.Join(channelIDs, ", "))
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	// Check which stored uploads are recent; only a complete crawl marks them synced
+	fresh := make(map[string]bool, len(channelIDs))
+	for _, channelID := range channelIDs {
+		stored, err := h.db.GetChannel(channelID)
+		if err != nil {
+			log.Printf("Error reading stored channel %s: %v", channelID, err)
+		}
+		fresh[channelID] = !refresh && stored != nil && stored.VideosFresh(policy.TTL)
+	}
+
+	apiChannels, err := h.fetchChannelsInfo(channelIDs)
+	if err != nil {
+		log.Printf("Error fetching channels for comparison: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var missing []string
+	for _, channelID := range channelIDs {
+		if apiChannels[channelID] == nil {
+			missing = append(missing, channelID)
+		}
+	}
+	if len(missing) > 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Channels not found: %s", strings.Join(missing, ", "))})
+		return
+	}
+
+	// Crawl outdated channels in parallel, reusing the channel info fetched above
+	videos := make([][]models.Video, len(channelIDs))
+	errs := make([]error, len(channelIDs))
+	var wg sync.WaitGroup
+	for i, channelID := range channelIDs {
+		wg.Add(1)
+		go func(i int, channelID string) {
+			defer wg.Done()
+			if !fresh[channelID] {
+				_, err, _ := h.inflight.Do(flightKey("crawl", channelID), func() (interface{}, error) {
+					return h.crawlUploads(channelID, apiChannels[channelID], nil)
+				})
+				if err != nil {
+					errs[i] = fmt.Errorf("failed to sync channel %s: %v", channelID, err)
+					return
+				}
+			}
+			videos[i], errs[i] = h.db.GetVideosByChannel(channelID)
+		}(i, channelID)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			log.Printf("Error loading videos for comparison: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	channels := make([]*models.Channel, len(channelIDs))
+	for i, channelID := range channelIDs {
+		channels[i] = channelFromAPI(apiChannels[channelID])
+	}
+
+	c.JSON(http.StatusOK, analytics.ComputeComparison(channels, videos, time.Now()))
+}
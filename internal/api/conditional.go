@@ -0,0 +1,122 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/models"
+)
+
+// respondConditional writes body as JSON with ETag, Last-Modified and Cache-Control
+// headers, or answers 304 Not Modified if the client's copy is still current.
+// An empty etag tags the encoded body, which suits bodies without per-response fields.
+func respondConditional(c *gin.Context, body interface{}, etag string, lastModified time.Time, cacheControl string) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		log.Printf("Error marshaling response: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode response"})
+		return
+	}
+
+	if etag == "" {
+		etag = etagOf(string(data))
+	}
+
+	c.Header("ETag", etag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		c.Header("Cache-Control", cacheControl)
+	}
+
+	if notModified(c.Request, etag, lastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// etagOf builds a strong entity tag from the values that identify a version of a response
+func etagOf(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// storedETag tags a stored analytics or trends result by the JSON it was stored as,
+// so the fresh response and every later cached copy of that version share one tag
+func storedETag(channelID string, engagementType models.EngagementType, stored []byte) string {
+	return etagOf(channelID, string(engagementType), string(stored))
+}
+
+// computedETag tags a result computed on request from a channel's stored videos. It
+// only changes when the videos are synced again or the query asks for something else,
+// never with the response's timestamp or cache metadata.
+func computedETag(c *gin.Context, channel *models.Channel) string {
+	return etagOf(c.FullPath(), channel.ID, channel.VideosSyncedAt.UTC().Format(time.RFC3339Nano), normalizedQuery(c.Request.URL.Query()))
+}
+
+// normalizedQuery encodes the query parameters that select a result in a fixed order,
+// leaving out empty values and ?refresh=, which only controls where the data comes from
+func normalizedQuery(query url.Values) string {
+	normalized := url.Values{}
+	for key, values := range query {
+		if key == "refresh" {
+			continue
+		}
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				normalized.Add(key, value)
+			}
+		}
+	}
+	return normalized.Encode()
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since as RFC 7232 requires
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil {
+			return false
+		}
+		return !lastModified.Truncate(time.Second).After(since)
+	}
+
+	return false
+}
+
+// maxAgeCacheControl builds a Cache-Control value for a response valid for maxAge
+func maxAgeCacheControl(maxAge, staleWhileRevalidate time.Duration) string {
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	value := fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds()))
+	if staleWhileRevalidate > 0 {
+		value += fmt.Sprintf(", stale-while-revalidate=%d", int(staleWhileRevalidate.Seconds()))
+	}
+	return value
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// GetChannelForecast projects a channel's subscribers and total views 30, 90 and 365
+// days ahead from its daily snapshots, along with its next subscriber milestone
+func (h *YouTubeAPI) GetChannelForecast(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Forecasting growth for channel: %s", channelID)
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	// Loading the channel also records today's snapshot when the stored copy is outdated
+	channel, _, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for forecast: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshots, err := h.db.GetChannelSnapshots(channelID)
+	if err != nil {
+		log.Printf("Error fetching snapshots for forecast: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	forecast := analytics.ComputeForecast(channel, snapshots, time.Now())
+	forecast.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, forecast, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/models"
+)
+
+const (
+	defaultHistoryLimit = 30
+	maxHistoryLimit     = 365
+)
+
+// GetChannelAnalyticsHistory returns dated versions of a channel's analytics
+func (h *YouTubeAPI) GetChannelAnalyticsHistory(c *gin.Context) {
+	h.getEngagementHistory(c, models.EngagementTypeAnalytics)
+}
+
+// GetChannelTrendsHistory returns dated versions of a channel's trends
+func (h *YouTubeAPI) GetChannelTrendsHistory(c *gin.Context) {
+	h.getEngagementHistory(c, models.EngagementTypeTrends)
+}
+
+// getEngagementHistory serves either a page of versions or, with ?as_of=, the single
+// version that was current on that date
+func (h *YouTubeAPI) getEngagementHistory(c *gin.Context, engagementType models.EngagementType) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	if asOfParam := c.Query("as_of"); asOfParam != "" {
+		asOf, err := parseAsOf(asOfParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		engagement, err := h.db.GetEngagementAsOf(channelID, engagementType, asOf)
+		if err != nil {
+			log.Printf("Error fetching %s as of %s: %v", engagementType, asOfParam, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if engagement == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("No %s recorded on or before %s", engagementType, asOfParam)})
+			return
+		}
+
+		c.JSON(http.StatusOK, engagement.Version())
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		if n > maxHistoryLimit {
+			n = maxHistoryLimit
+		}
+		limit = n
+	}
+
+	offset := 0
+	if o := c.Query("offset"); o != "" {
+		n, err := strconv.Atoi(o)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset must be a non-negative integer"})
+			return
+		}
+		offset = n
+	}
+
+	total, err := h.db.CountEngagementHistory(channelID, engagementType)
+	if err != nil {
+		log.Printf("Error counting %s history: %v", engagementType, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	engagements, err := h.db.GetEngagementHistory(channelID, engagementType, limit, offset)
+	if err != nil {
+		log.Printf("Error fetching %s history: %v", engagementType, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	versions := make([]models.EngagementVersion, 0, len(engagements))
+	for _, engagement := range engagements {
+		versions = append(versions, engagement.Version())
+	}
+
+	c.JSON(http.StatusOK, models.EngagementHistory{
+		ChannelID:      channelID,
+		EngagementType: engagementType,
+		Total:          total,
+		Limit:          limit,
+		Offset:         offset,
+		Versions:       versions,
+	})
+}
+
+// parseAsOf accepts either a plain date or a full RFC3339 timestamp
+func parseAsOf(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("as_of must be a date (YYYY-MM-DD) or an RFC3339 timestamp")
+}
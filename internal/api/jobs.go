@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/models"
+	"github.com/yt-insights/internal/scheduler"
+)
+
+const (
+	defaultJobRunsLimit = 50
+	maxJobRunsLimit     = 500
+)
+
+// JobsHandler exposes the background scheduler and its run history
+type JobsHandler struct {
+	scheduler *scheduler.Scheduler
+	db        *models.Database
+}
+
+// NewJobsHandler creates a new jobs handler
+func NewJobsHandler(s *scheduler.Scheduler, db *models.Database) *JobsHandler {
+	return &JobsHandler{scheduler: s, db: db}
+}
+
+// GetJobs returns the scheduler status and the most recent job runs
+func (j *JobsHandler) GetJobs(c *gin.Context) {
+	limit := defaultJobRunsLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+		if n > maxJobRunsLimit {
+			n = maxJobRunsLimit
+		}
+		limit = n
+	}
+
+	runs, err := j.db.GetRecentJobRuns(limit)
+	if err != nil {
+		log.Printf("Error fetching job runs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"scheduler": j.scheduler.Status(),
+		"runs":      runs,
+	})
+}
+
+// GetJob returns the status, progress and, once finished, the result of an analytics job
+func (j *JobsHandler) GetJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	job, err := j.db.GetAnalyticsJob(jobID)
+	if err != nil {
+		log.Printf("Error fetching job %s: %v", jobID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if job == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// RunRefresh starts a refresh of the watchlist in the background
+func (j *JobsHandler) RunRefresh(c *gin.Context) {
+	if j.scheduler.Status().Running {
+		c.JSON(http.StatusConflict, gin.H{"error": scheduler.ErrRunInProgress.Error()})
+		return
+	}
+
+	go func() {
+		if _, err := j.scheduler.RunOnce(context.Background()); err != nil {
+			log.Printf("Manual refresh failed: %v", err)
+		}
+	}()
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Refresh started"})
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// maxOutlierWindow bounds the number of neighbouring uploads in a baseline
+const maxOutlierWindow = 200
+
+// GetChannelOutliers lists the videos that perform far above or below the uploads
+// published around them. ?sensitivity= sets the robust z-score threshold (default 3.5),
+// ?window= the number of neighbouring uploads in each baseline (default 10) and
+// ?all=true adds every video's baseline to the response.
+func (h *YouTubeAPI) GetChannelOutliers(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sensitivity := analytics.DefaultOutlierSensitivity
+	if s := c.Query("sensitivity"); s != "" {
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sensitivity must be a positive number"})
+			return
+		}
+		sensitivity = n
+	}
+
+	window := analytics.DefaultOutlierWindow
+	if w := c.Query("window"); w != "" {
+		n, err := strconv.Atoi(w)
+		if err != nil || n < 2 || n > maxOutlierWindow {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("window must be an integer between 2 and %d", maxOutlierWindow)})
+			return
+		}
+		window = n
+	}
+
+	all := false
+	if a := c.Query("all"); a != "" {
+		if all, err = strconv.ParseBool(a); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "all must be true or false"})
+			return
+		}
+	}
+
+	log.Printf("Detecting outliers for channel: %s", channelID)
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for outliers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	outliers := analytics.ComputeOutliers(channel, videos, window, sensitivity, time.Now())
+	if !all {
+		outliers.Videos = nil
+	}
+	outliers.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, outliers, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
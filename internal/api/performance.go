@@ -0,0 +1,57 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// maxEarlyDays bounds the N in "views in the first N days"
+const maxEarlyDays = 365
+
+// GetChannelPerformance lists every video of a channel with its views normalized by age:
+// views per day, views in the first ?days= days (7 by default) and performance against
+// the views expected for its age, best performing first
+func (h *YouTubeAPI) GetChannelPerformance(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	earlyDays := analytics.DefaultEarlyDays
+	if d := c.Query("days"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 || n > maxEarlyDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("days must be an integer between 1 and %d", maxEarlyDays)})
+			return
+		}
+		earlyDays = n
+	}
+
+	log.Printf("Computing age-normalized performance for channel: %s", channelID)
+	policy := h.cachePolicies[models.EngagementTypeTrends]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for performance: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	performance := analytics.ComputeAgePerformance(channel, videos, h.videoSnapshots(channelID, earlyDays), earlyDays, time.Now())
+	performance.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, performance, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
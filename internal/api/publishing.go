@@ -0,0 +1,48 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// GetChannelPublishing returns a weekday by hour heatmap of a channel's uploads and
+// the best window to publish in. ?tz= sets the IANA time zone the hours are
+// read in, UTC by default.
+func (h *YouTubeAPI) GetChannelPublishing(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loc, err := parseTimezone(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	log.Printf("Building publishing heatmap for channel %s in %s", channelID, loc)
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for publishing heatmap: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	heatmap := analytics.ComputePublishing(channel, videos, loc, time.Now())
+	heatmap.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, heatmap, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
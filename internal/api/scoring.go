@@ -0,0 +1,47 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// parseScoring reads the optional ?scoring= model. It returns nil when the request
+// asks for the configured model, whose results are cached and stored as usual.
+func (h *YouTubeAPI) parseScoring(c *gin.Context) (analytics.Scorer, error) {
+	name := c.Query("scoring")
+	if name == "" {
+		return nil, nil
+	}
+
+	scorer, err := analytics.NewScorer(name, h.scoringWeights)
+	if err != nil {
+		return nil, err
+	}
+	if scorer.Model().Name == h.scorer.Model().Name {
+		return nil, nil
+	}
+	return scorer, nil
+}
+
+// respondScoredAnalytics serves analytics ranked with a model other than the configured one.
+// They are computed from the stored tables on every request and never stored themselves.
+func (h *YouTubeAPI) respondScoredAnalytics(c *gin.Context, channelID string, scorer analytics.Scorer, refresh bool) {
+	log.Printf("Computing analytics for channel %s with %s scoring", channelID, scorer.Model().Name)
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for scored analytics: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := analytics.ComputeAnalytics(channel, videos, h.videoSnapshots(channelID, analytics.DefaultEarlyDays), time.Now(), scorer)
+	result.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, result, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
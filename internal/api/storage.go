@@ -0,0 +1,45 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/retention"
+)
+
+// StorageHandler exposes retention and compaction of stored analytics data
+type StorageHandler struct {
+	compactor *retention.Compactor
+}
+
+// NewStorageHandler creates a new storage handler
+func NewStorageHandler(compactor *retention.Compactor) *StorageHandler {
+	return &StorageHandler{compactor: compactor}
+}
+
+// GetCompactionStats returns the stats of the last compaction run
+func (s *StorageHandler) GetCompactionStats(c *gin.Context) {
+	stats := s.compactor.LastStats()
+	if stats == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No compaction has run yet"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// RunCompaction triggers a compaction run and returns its stats
+func (s *StorageHandler) RunCompaction(c *gin.Context) {
+	stats, err := s.compactor.RunOnce()
+	if errors.Is(err, retention.ErrRunInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Manual compaction failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "stats": stats})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// maxTagLimit bounds how many tags each ranking lists
+const maxTagLimit = 200
+
+// GetChannelTags ranks a channel's tags and hashtags by frequency and by performance.
+// ?min= sets the fewest videos a tag needs to be ranked by performance (3 by default)
+// and ?limit= how many tags each ranking lists (25 by default).
+func (h *YouTubeAPI) GetChannelTags(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minVideos := analytics.DefaultMinTagVideos
+	if m := c.Query("min"); m != "" {
+		n, err := strconv.Atoi(m)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min must be a positive integer"})
+			return
+		}
+		minVideos = n
+	}
+
+	limit := analytics.DefaultTagLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > maxTagLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be an integer between 1 and %d", maxTagLimit)})
+			return
+		}
+		limit = n
+	}
+
+	log.Printf("Analyzing tags for channel: %s", channelID)
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for tag analysis: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags := analytics.ComputeTags(channel, videos, minVideos, limit, time.Now())
+	tags.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, tags, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
+
+// CompareWatchlistTags finds the tags and hashtags the watchlisted channels share.
+// It reads only stored data; channels not crawled yet are left out.
+func (h *YouTubeAPI) CompareWatchlistTags(c *gin.Context) {
+	entries, err := h.db.GetWatchlist()
+	if err != nil {
+		log.Printf("Error reading watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var channels []*models.Channel
+	var videos [][]models.Video
+	for _, entry := range entries {
+		channel, err := h.db.GetChannel(entry.ChannelID)
+		if err != nil {
+			log.Printf("Error reading stored channel %s: %v", entry.ChannelID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if channel == nil || channel.VideosSyncedAt.IsZero() {
+			log.Printf("Channel %s has not been crawled yet, leaving it out of the tag comparison", entry.ChannelID)
+			continue
+		}
+
+		channelVideos, err := h.db.GetVideosByChannel(entry.ChannelID)
+		if err != nil {
+			log.Printf("Error reading videos of channel %s: %v", entry.ChannelID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		channels = append(channels, channel)
+		videos = append(videos, channelVideos)
+	}
+
+	c.JSON(http.StatusOK, analytics.CompareTags(channels, videos, time.Now()))
+}
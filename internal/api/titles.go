@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// maxTermLimit bounds how many terms of each length one response lists
+const maxTermLimit = 200
+
+// GetChannelTitles analyzes a channel's video titles: the words and phrases used in
+// at least ?min= titles (3 by default), up to ?limit= of each length (25 by default),
+// and how structural features of titles relate to views
+func (h *YouTubeAPI) GetChannelTitles(c *gin.Context) {
+	channelID := c.Param("id")
+	if channelID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Channel ID is required"})
+		return
+	}
+
+	refresh, err := parseRefresh(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minVideos := analytics.DefaultMinTermVideos
+	if m := c.Query("min"); m != "" {
+		n, err := strconv.Atoi(m)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min must be a positive integer"})
+			return
+		}
+		minVideos = n
+	}
+
+	limit := analytics.DefaultTermLimit
+	if l := c.Query("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > maxTermLimit {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be an integer between 1 and %d", maxTermLimit)})
+			return
+		}
+		limit = n
+	}
+
+	log.Printf("Analyzing titles for channel: %s", channelID)
+	policy := h.cachePolicies[models.EngagementTypeAnalytics]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for title analysis: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	titles := analytics.ComputeTitles(channel, videos, minVideos, limit, time.Now())
+	titles.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, titles, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/analytics"
+	"github.com/yt-insights/internal/models"
+)
+
+// parseTrendOptions reads the optional ?scoring=, ?granularity= and ?tz= parameters of
+// a trends request. custom is false when they all match the stored trends, which are
+// ranked with the configured model and bucketed by month in UTC.
+func (h *YouTubeAPI) parseTrendOptions(c *gin.Context) (opts analytics.TrendOptions, custom bool, err error) {
+	scorer, err := h.parseScoring(c)
+	if err != nil {
+		return opts, false, err
+	}
+
+	granularity := analytics.DefaultGranularity
+	if value := c.Query("granularity"); value != "" {
+		if granularity, err = analytics.ParseGranularity(value); err != nil {
+			return opts, false, err
+		}
+	}
+
+	loc, err := parseTimezone(c)
+	if err != nil {
+		return opts, false, err
+	}
+
+	custom = scorer != nil || granularity != analytics.DefaultGranularity || loc.String() != time.UTC.String()
+	if scorer == nil {
+		scorer = h.scorer
+	}
+	return analytics.TrendOptions{Scorer: scorer, Granularity: granularity, Location: loc}, custom, nil
+}
+
+// respondCustomTrends serves trends ranked or bucketed differently from the stored ones.
+// They are computed from the stored tables on every request and never stored themselves.
+func (h *YouTubeAPI) respondCustomTrends(c *gin.Context, channelID string, opts analytics.TrendOptions, refresh bool) {
+	log.Printf("Computing trends for channel %s with %s scoring by %s in %s",
+		channelID, opts.Scorer.Model().Name, opts.Granularity, opts.Location)
+	policy := h.cachePolicies[models.EngagementTypeTrends]
+
+	channel, videos, fromCache, err := h.loadChannelForPolicy(channelID, policy, refresh)
+	if err != nil {
+		log.Printf("Error loading channel for custom trends: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := analytics.CheckPeriods(videos, opts.Granularity, opts.Location); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := analytics.ComputeTrends(channel, videos, h.videoSnapshots(channelID, analytics.DefaultEarlyDays), time.Now(), opts)
+	result.CacheMetadata = policy.metadata(channel.VideosSyncedAt, fromCache)
+	respondConditional(c, result, computedETag(c, channel), channel.VideosSyncedAt, policy.cacheControl(channel.VideosSyncedAt))
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/models"
+)
+
+// channelIDPattern matches a raw YouTube channel ID
+var channelIDPattern = regexp.MustCompile(`^UC[0-9A-Za-z_-]{22}$`)
+
+// resolveChannelRef turns a channel ID, an @handle or any supported channel URL into a channel ID
+func (y *YouTubeAPI) resolveChannelRef(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	switch {
+	case ref == "":
+		return "", fmt.Errorf("channel is required")
+	case channelIDPattern.MatchString(ref):
+		return ref, nil
+	case strings.HasPrefix(ref, "@"):
+		ref = "https://www.youtube.com/" + ref
+	case !strings.Contains(ref, "://"):
+		ref = "https://" + ref
+	}
+	return y.resolveChannelURL(ref)
+}
+
+// AddToWatchlist tracks a channel, or updates its labels and notes if already tracked
+func (y *YouTubeAPI) AddToWatchlist(c *gin.Context) {
+	var req models.WatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request: %v", err)})
+		return
+	}
+
+	channelID, err := y.resolveChannelRef(req.Channel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid channel: %v", err)})
+		return
+	}
+
+	// Make sure the channel exists before tracking it
+	channel, err := y.getChannelInfo(channelID)
+	if errors.Is(err, errChannelNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel not found"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching channel info for watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Error fetching channel info: %v", err)})
+		return
+	}
+
+	existing, err := y.db.GetWatchlistEntry(channelID)
+	if err != nil {
+		log.Printf("Error reading watchlist entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels := make([]string, 0, len(req.Labels))
+	for _, label := range req.Labels {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	sourceURL := ""
+	if !channelIDPattern.MatchString(strings.TrimSpace(req.Channel)) {
+		sourceURL = strings.TrimSpace(req.Channel)
+	}
+
+	if err := y.db.UpsertWatchlistEntry(&models.WatchlistEntry{
+		ChannelID:    channelID,
+		ChannelTitle: channel.Snippet.Title,
+		SourceURL:    sourceURL,
+		Labels:       labels,
+		Notes:        req.Notes,
+	}); err != nil {
+		log.Printf("Error storing watchlist entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := y.db.GetWatchlistEntry(channelID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusCreated
+	if existing != nil {
+		status = http.StatusOK
+	}
+	c.JSON(status, entry)
+}
+
+// GetWatchlist lists every tracked channel
+func (y *YouTubeAPI) GetWatchlist(c *gin.Context) {
+	entries, err := y.db.GetWatchlist()
+	if err != nil {
+		log.Printf("Error reading watchlist: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Optionally narrow the list to channels carrying a label
+	if label := c.Query("label"); label != "" {
+		filtered := make([]*models.WatchlistEntry, 0, len(entries))
+		for _, entry := range entries {
+			for _, l := range entry.Labels {
+				if strings.EqualFold(l, label) {
+					filtered = append(filtered, entry)
+					break
+				}
+			}
+		}
+		entries = filtered
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// GetWatchlistEntry returns a single tracked channel
+func (y *YouTubeAPI) GetWatchlistEntry(c *gin.Context) {
+	channelID, err := y.resolveChannelRef(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid channel: %v", err)})
+		return
+	}
+
+	entry, err := y.db.GetWatchlistEntry(channelID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if entry == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel is not on the watchlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// RemoveFromWatchlist stops tracking a channel
+func (y *YouTubeAPI) RemoveFromWatchlist(c *gin.Context) {
+	channelID, err := y.resolveChannelRef(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid channel: %v", err)})
+		return
+	}
+
+	removed, err := y.db.DeleteWatchlistEntry(channelID)
+	if err != nil {
+		log.Printf("Error removing watchlist entry: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel is not on the watchlist"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/yt-insights/internal/cache"
+	"github.com/yt-insights/internal/models"
+	"github.com/yt-insights/internal/websub"
+)
+
+// maxNotificationBytes bounds the size of a pushed Atom notification
+const maxNotificationBytes = 1 << 20
+
+// WebSubHandler receives push notifications from the WebSub hub
+type WebSubHandler struct {
+	subscriber *websub.Subscriber
+	youtube    *YouTubeAPI
+	db         *models.Database
+}
+
+// NewWebSubHandler creates a new WebSub handler
+func NewWebSubHandler(subscriber *websub.Subscriber, youtube *YouTubeAPI, db *models.Database) *WebSubHandler {
+	return &WebSubHandler{subscriber: subscriber, youtube: youtube, db: db}
+}
+
+// VerifySubscription answers the hub's GET intent verification by echoing hub.challenge
+func (w *WebSubHandler) VerifySubscription(c *gin.Context) {
+	mode := c.Query("hub.mode")
+	topic := c.Query("hub.topic")
+
+	var leaseSeconds int64
+	if lease := c.Query("hub.lease_seconds"); lease != "" {
+		n, err := strconv.ParseInt(lease, 10, 64)
+		if err != nil {
+			c.String(http.StatusBadRequest, "invalid hub.lease_seconds")
+			return
+		}
+		leaseSeconds = n
+	}
+
+	ok, err := w.subscriber.Verify(mode, topic, leaseSeconds)
+	if err != nil {
+		log.Printf("Error verifying WebSub %s of %s: %v", mode, topic, err)
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !ok {
+		log.Printf("Rejected WebSub %s verification for unknown topic %s", mode, topic)
+		c.String(http.StatusNotFound, "unknown subscription")
+		return
+	}
+
+	if mode == "denied" {
+		log.Printf("Hub denied subscription to %s: %s", topic, c.Query("hub.reason"))
+		c.Status(http.StatusOK)
+		return
+	}
+
+	log.Printf("Verified WebSub %s of %s", mode, topic)
+	c.String(http.StatusOK, c.Query("hub.challenge"))
+}
+
+// ReceiveNotification accepts an Atom push notification and fetches each announced video.
+// Per the WebSub spec the hub always gets a 2xx, even for notifications we discard.
+func (w *WebSubHandler) ReceiveNotification(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxNotificationBytes))
+	if err != nil {
+		log.Printf("Error reading WebSub notification: %v", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	if !w.subscriber.VerifySignature(c.GetHeader("X-Hub-Signature"), body) {
+		log.Printf("Discarding WebSub notification with invalid signature")
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	feed, err := websub.ParseFeed(body)
+	if err != nil {
+		log.Printf("Error parsing WebSub notification: %v", err)
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	for _, deleted := range feed.Deleted {
+		videoID := deleted.VideoID()
+		if videoID == "" {
+			continue
+		}
+
+		// Only the hub of a channel we subscribed to may remove its videos
+		channelID, err := w.db.GetVideoChannelID(videoID)
+		if err != nil {
+			log.Printf("Error looking up deleted video %s: %v", videoID, err)
+			continue
+		}
+		if channelID == "" || !w.subscribed(channelID) {
+			log.Printf("Ignoring deletion of video %s outside the subscribed channels", videoID)
+			continue
+		}
+
+		log.Printf("Hub reported video %s deleted at %s", videoID, deleted.When)
+		go func(videoID string) {
+			if err := w.youtube.RemoveVideo(videoID); err != nil {
+				log.Printf("Failed to remove video %s: %v", videoID, err)
+			}
+		}(videoID)
+	}
+
+	for _, entry := range feed.Entries {
+		channelID := entry.ChannelID
+		if channelID == "" {
+			channelID = websub.ChannelIDFromTopic(feed.Topic())
+		}
+		if entry.VideoID == "" || channelID == "" {
+			continue
+		}
+
+		if !w.subscribed(channelID) {
+			log.Printf("Ignoring notification for unsubscribed channel %s", channelID)
+			continue
+		}
+		if err := w.db.TouchWebSubSubscription(channelID); err != nil {
+			log.Printf("Failed to record notification for channel %s: %v", channelID, err)
+		}
+
+		log.Printf("WebSub notification: video %s (%q) on channel %s", entry.VideoID, entry.Title, channelID)
+		go func(channelID, videoID string) {
+			if err := w.youtube.IngestVideo(channelID, videoID); err != nil {
+				log.Printf("Failed to ingest video %s: %v", videoID, err)
+			}
+		}(channelID, entry.VideoID)
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// subscribed reports whether the hub has verified a subscription to the channel
+func (w *WebSubHandler) subscribed(channelID string) bool {
+	sub, err := w.db.GetWebSubSubscriptionByTopic(websub.TopicURL(channelID))
+	if err != nil {
+		log.Printf("Error fetching WebSub subscription for channel %s: %v", channelID, err)
+		return false
+	}
+	return sub != nil && sub.Status == models.WebSubStatusActive
+}
+
+// GetSubscriptions lists the hub subscriptions and their lease state
+func (w *WebSubHandler) GetSubscriptions(c *gin.Context) {
+	subs, err := w.db.GetWebSubSubscriptions()
+	if err != nil {
+		log.Printf("Error fetching WebSub subscriptions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// SyncSubscriptions brings the hub subscriptions in line with the watchlist right away
+func (w *WebSubHandler) SyncSubscriptions(c *gin.Context) {
+	if err := w.subscriber.Sync(); err != nil {
+		log.Printf("WebSub sync failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	w.GetSubscriptions(c)
+}
+
+// IngestVideo fetches a single video and merges it into the stored channel, then recomputes
+// the channel's analytics and trends from the tables without a full crawl
+func (y *YouTubeAPI) IngestVideo(channelID, videoID string) error {
+	response, err := y.service.Videos.List([]string{"snippet", "statistics", "contentDetails"}).Id(videoID).Do()
+	if err != nil {
+		return fmt.Errorf("error fetching video details: %v", err)
+	}
+	if len(response.Items) == 0 {
+		return fmt.Errorf("video not found")
+	}
+
+	item := response.Items[0]
+	if item.Snippet == nil || item.Statistics == nil || item.ContentDetails == nil {
+		return fmt.Errorf("video %s is missing details", videoID)
+	}
+	video := videoFromAPI(item)
+	if video.ChannelID != "" {
+		channelID = video.ChannelID
+	}
+
+	if err := y.db.UpsertVideos(channelID, []models.Video{video}); err != nil {
+		return err
+	}
+	if err := y.db.RecordVideoSnapshots(channelID, []models.Video{video}); err != nil {
+		log.Printf("Failed to record snapshot of video %s: %v", videoID, err)
+	}
+
+	// Only a channel whose uploads were fully crawled has a complete table to serve from.
+	// A watchlisted channel has a stored row before its first crawl, so the sync time
+	// decides; otherwise drop the cached list so the next request crawls.
+	channel, err := y.db.GetChannel(channelID)
+	if err != nil || channel == nil || channel.VideosSyncedAt.IsZero() {
+		y.cache.Invalidate(cache.ResourceVideos, channelID)
+		return err
+	}
+
+	videos, err := y.db.GetVideosByChannel(channelID)
+	if err != nil {
+		y.cache.Invalidate(cache.ResourceVideos, channelID)
+		return err
+	}
+	y.cache.Set(cache.ResourceVideos, channelID, videos)
+
+	if _, err := y.refreshAnalytics(channelID, false); err != nil {
+		log.Printf("Failed to refresh analytics after new upload on channel %s: %v", channelID, err)
+	}
+	if _, err := y.refreshTrends(channelID, false); err != nil {
+		log.Printf("Failed to refresh trends after new upload on channel %s: %v", channelID, err)
+	}
+
+	log.Printf("Ingested video %s for channel %s", videoID, channelID)
+	return nil
+}
+
+// RemoveVideo drops a deleted or privated video from the stored channel, then recomputes
+// the channel's analytics and trends from the tables without a full crawl
+func (y *YouTubeAPI) RemoveVideo(videoID string) error {
+	channelID, err := y.db.DeleteVideo(videoID)
+	if err != nil {
+		return err
+	}
+	if channelID == "" {
+		return nil
+	}
+	y.cache.Invalidate(cache.ResourceVideos, channelID)
+
+	// A channel that was never fully crawled has no stored results to correct
+	channel, err := y.db.GetChannel(channelID)
+	if err != nil || channel == nil || channel.VideosSyncedAt.IsZero() {
+		return err
+	}
+
+	if _, err := y.refreshAnalytics(channelID, false); err != nil {
+		log.Printf("Failed to refresh analytics after removing video from channel %s: %v", channelID, err)
+	}
+	if _, err := y.refreshTrends(channelID, false); err != nil {
+		log.Printf("Failed to refresh trends after removing video from channel %s: %v", channelID, err)
+	}
+
+	log.Printf("Removed video %s from channel %s", videoID, channelID)
+	return nil
+}
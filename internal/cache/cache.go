@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"encoding/json"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// Resource identifies a kind of cached data, each with its own TTL
+type Resource string
+
+const (
+	ResourceChannel Resource = "channel"
+	ResourceResolve Resource = "resolve"
+	ResourceVideos  Resource = "videos"
+)
+
+// Store is the persistent tier behind the in-process LRU
+type Store interface {
+	GetCacheEntry(key string) (*models.CacheEntry, error)
+	SetCacheEntry(entry *models.CacheEntry) error
+	DeleteCacheEntry(key string) error
+}
+
+// counters tracks hits and misses for one resource
+type counters struct {
+	memoryHits atomic.Int64
+	storeHits  atomic.Int64
+	misses     atomic.Int64
+	sets       atomic.Int64
+}
+
+// ResourceStats reports cache effectiveness for one resource
+type ResourceStats struct {
+	TTL        string  `json:"ttl"`
+	MemoryHits int64   `json:"memoryHits"`
+	StoreHits  int64   `json:"storeHits"`
+	Misses     int64   `json:"misses"`
+	Sets       int64   `json:"sets"`
+	HitRatio   float64 `json:"hitRatio"`
+}
+
+// Stats reports cache effectiveness across all resources
+type Stats struct {
+	MemoryEntries int                        `json:"memoryEntries"`
+	Resources     map[Resource]ResourceStats `json:"resources"`
+}
+
+// Cache is a two-tier cache: an in-process LRU in front of a persistent store
+type Cache struct {
+	memory   *LRU
+	store    Store
+	ttls     map[Resource]time.Duration
+	counters map[Resource]*counters
+}
+
+// New creates a cache. A resource with a zero TTL is never cached.
+func New(memoryCapacity int, store Store, ttls map[Resource]time.Duration) *Cache {
+	c := &Cache{
+		memory:   NewLRU(memoryCapacity),
+		store:    store,
+		ttls:     ttls,
+		counters: make(map[Resource]*counters),
+	}
+	for resource := range ttls {
+		c.counters[resource] = &counters{}
+	}
+	return c
+}
+
+// TTL returns how long values of a resource are cached
+func (c *Cache) TTL(resource Resource) time.Duration {
+	return c.ttls[resource]
+}
+
+// Get decodes a cached value into dest and returns when it was stored
+func (c *Cache) Get(resource Resource, key string, dest interface{}) (time.Time, bool) {
+	counter := c.counter(resource)
+	if c.ttls[resource] <= 0 {
+		return time.Time{}, false
+	}
+
+	fullKey := cacheKey(resource, key)
+	now := time.Now()
+
+	if data, storedAt, ok := c.memory.Get(fullKey, now); ok {
+		if err := json.Unmarshal(data, dest); err == nil {
+			counter.memoryHits.Add(1)
+			return storedAt, true
+		}
+		c.memory.Delete(fullKey)
+	}
+
+	if c.store != nil {
+		entry, err := c.store.GetCacheEntry(fullKey)
+		if err != nil {
+			log.Printf("Error reading cache entry %s: %v", fullKey, err)
+		} else if entry != nil && now.Before(entry.ExpiresAt) {
+			if err := json.Unmarshal(entry.Value, dest); err == nil {
+				c.memory.Set(fullKey, entry.Value, entry.StoredAt, entry.ExpiresAt)
+				counter.storeHits.Add(1)
+				return entry.StoredAt, true
+			}
+		}
+	}
+
+	counter.misses.Add(1)
+	return time.Time{}, false
+}
+
+// Set stores a value in both tiers using the resource's TTL
+func (c *Cache) Set(resource Resource, key string, value interface{}) {
+	ttl := c.ttls[resource]
+	if ttl <= 0 {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Error encoding cache value for %s/%s: %v", resource, key, err)
+		return
+	}
+
+	fullKey := cacheKey(resource, key)
+	storedAt := time.Now().UTC().Truncate(time.Second)
+	expiresAt := storedAt.Add(ttl)
+
+	c.memory.Set(fullKey, data, storedAt, expiresAt)
+	c.counter(resource).sets.Add(1)
+
+	if c.store != nil {
+		if err := c.store.SetCacheEntry(&models.CacheEntry{
+			Key:       fullKey,
+			Resource:  string(resource),
+			Value:     data,
+			StoredAt:  storedAt,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			log.Printf("Error writing cache entry %s: %v", fullKey, err)
+		}
+	}
+}
+
+// Invalidate removes a value from both tiers
+func (c *Cache) Invalidate(resource Resource, key string) {
+	fullKey := cacheKey(resource, key)
+	c.memory.Delete(fullKey)
+	if c.store != nil {
+		if err := c.store.DeleteCacheEntry(fullKey); err != nil {
+			log.Printf("Error deleting cache entry %s: %v", fullKey, err)
+		}
+	}
+}
+
+// Stats returns hit and miss counts per resource
+func (c *Cache) Stats() Stats {
+	stats := Stats{
+		MemoryEntries: c.memory.Len(),
+		Resources:     make(map[Resource]ResourceStats),
+	}
+	for resource, counter := range c.counters {
+		s := ResourceStats{
+			TTL:        c.ttls[resource].String(),
+			MemoryHits: counter.memoryHits.Load(),
+			StoreHits:  counter.storeHits.Load(),
+			Misses:     counter.misses.Load(),
+			Sets:       counter.sets.Load(),
+		}
+		if total := s.MemoryHits + s.StoreHits + s.Misses; total > 0 {
+			s.HitRatio = float64(s.MemoryHits+s.StoreHits) / float64(total)
+		}
+		stats.Resources[resource] = s
+	}
+	return stats
+}
+
+// counter returns the counters for a resource; unknown resources share a throwaway set
+func (c *Cache) counter(resource Resource) *counters {
+	if counter, ok := c.counters[resource]; ok {
+		return counter
+	}
+	return &counters{}
+}
+
+// cacheKey namespaces a key by resource
+func cacheKey(resource Resource, key string) string {
+	return string(resource) + ":" + key
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// lruEntry is a single value held in memory
+type lruEntry struct {
+	key       string
+	value     []byte
+	storedAt  time.Time
+	expiresAt time.Time
+}
+
+// LRU is a fixed-size in-process cache that evicts the least recently used entry
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// NewLRU creates an LRU holding at most capacity entries
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns an unexpired value and the time it was stored
+func (l *LRU) Get(key string, now time.Time) ([]byte, time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+
+	entry := element.Value.(*lruEntry)
+	if !now.Before(entry.expiresAt) {
+		l.order.Remove(element)
+		delete(l.items, key)
+		return nil, time.Time{}, false
+	}
+
+	l.order.MoveToFront(element)
+	return entry.value, entry.storedAt, true
+}
+
+// Set stores a value until expiresAt, evicting the oldest entry if full
+func (l *LRU) Set(key string, value []byte, storedAt, expiresAt time.Time) {
+	if l.capacity <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		element.Value = &lruEntry{key: key, value: value, storedAt: storedAt, expiresAt: expiresAt}
+		l.order.MoveToFront(element)
+		return
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, storedAt: storedAt, expiresAt: expiresAt})
+
+	for l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Delete removes a value if present
+func (l *LRU) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		l.order.Remove(element)
+		delete(l.items, key)
+	}
+}
+
+// Len returns the number of entries currently held
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
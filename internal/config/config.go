@@ -1,51 +1,293 @@
-package config
-
-import (
-	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
-)
-
-var (
-	ErrMissingAPIKey = errors.New("YouTube API key is required")
-)
-
-// Config holds the application configuration
-type Config struct {
-	YouTubeAPIKey string
-	DBPath        string
-}
-
-// Load loads the configuration from environment variables
-func Load() (*Config, error) {
-	// Get YouTube API key from environment
-	apiKey := os.Getenv("YOUTUBE_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("YOUTUBE_API_KEY environment variable is required")
-	}
-
-	// Get database path from environment or use default
-	dbPath := os.Getenv("DB_PATH")
-	if dbPath == "" {
-		// Use the pre-compiled SQLite setup path
-		wd, err := os.Getwd()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get working directory: %v", err)
-		}
-		dbPath = filepath.Join(wd, "..", "sqlite", "yt_insights.db")
-	}
-
-	return &Config{
-		YouTubeAPIKey: apiKey,
-		DBPath:        dbPath,
-	}, nil
-}
-
-// Validate checks if the configuration is valid
-func (c *Config) Validate() error {
-	if c.YouTubeAPIKey == "" {
-		return fmt.Errorf("%w: YOUTUBE_API_KEY environment variable is not set", ErrMissingAPIKey)
-	}
-	return nil
-}
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrMissingAPIKey = errors.New("YouTube API key is required")
+)
+
+// Config holds the application configuration
+type Config struct {
+	YouTubeAPIKey string
+	DBPath        string
+
+	// Retention policy for stored analytics payloads
+	RetentionDailyDays   int
+	RetentionWeeklyDays  int
+	RetentionMonthlyDays int
+	CompactionInterval   time.Duration
+	CompressPayloads     bool
+
+	// Cache freshness per endpoint; stale data is served while revalidating
+	// for up to the stale-while-revalidate window after the TTL expires
+	AnalyticsCacheTTL             time.Duration
+	AnalyticsStaleWhileRevalidate time.Duration
+	TrendsCacheTTL                time.Duration
+	TrendsStaleWhileRevalidate    time.Duration
+
+	// Shared cache for channel info, URL resolutions and video lists
+	CacheMemoryEntries int
+	ChannelCacheTTL    time.Duration
+	ResolveCacheTTL    time.Duration
+	VideosCacheTTL     time.Duration
+
+	// Background refresh of watchlisted channels
+	SchedulerEnabled    bool
+	RefreshSchedule     string
+	RefreshSpread       time.Duration
+	SchedulerTimezone   string
+	SchedulerDailyQuota int
+
+	// WebSub push notifications for new uploads on watchlisted channels
+	WebSubEnabled       bool
+	WebSubHubURL        string
+	WebSubCallbackURL   string
+	WebSubSecret        string
+	WebSubLease         time.Duration
+	WebSubRenewInterval time.Duration
+
+	// Alert rule evaluation and webhook delivery
+	AlertsInterval      time.Duration
+	WebhookTimeout      time.Duration
+	WebhookMaxAttempts  int
+	WebhookRetryBackoff time.Duration
+
+	// Engagement scoring used to rank videos in analytics and trends
+	ScoringDefault        string
+	ScoringWeightViews    float64
+	ScoringWeightLikes    float64
+	ScoringWeightComments float64
+}
+
+// Load loads the configuration from environment variables
+func Load() (*Config, error) {
+	// Get YouTube API key from environment
+	apiKey := os.Getenv("YOUTUBE_API_KEY")
+	if apiKey == "" {
+		return nil, fmt.Errorf("YOUTUBE_API_KEY environment variable is required")
+	}
+
+	// Get database path from environment or use default
+	dbPath := os.Getenv("DB_PATH")
+	if dbPath == "" {
+		// Use the pre-compiled SQLite setup path
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %v", err)
+		}
+		dbPath = filepath.Join(wd, "..", "sqlite", "yt_insights.db")
+	}
+
+	cfg := &Config{
+		YouTubeAPIKey: apiKey,
+		DBPath:        dbPath,
+	}
+
+	// Retention: keep dailies for 90 days, weeklies for a year, monthlies forever
+	var err error
+	if cfg.RetentionDailyDays, err = getEnvInt("RETENTION_DAILY_DAYS", 90); err != nil {
+		return nil, err
+	}
+	if cfg.RetentionWeeklyDays, err = getEnvInt("RETENTION_WEEKLY_DAYS", 365); err != nil {
+		return nil, err
+	}
+	if cfg.RetentionMonthlyDays, err = getEnvInt("RETENTION_MONTHLY_DAYS", 0); err != nil {
+		return nil, err
+	}
+	if cfg.CompactionInterval, err = getEnvDuration("COMPACTION_INTERVAL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.CompressPayloads, err = getEnvBool("COMPRESS_PAYLOADS", false); err != nil {
+		return nil, err
+	}
+
+	// Cache freshness
+	if cfg.AnalyticsCacheTTL, err = getEnvDuration("ANALYTICS_CACHE_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.AnalyticsStaleWhileRevalidate, err = getEnvDuration("ANALYTICS_STALE_WHILE_REVALIDATE", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrendsCacheTTL, err = getEnvDuration("TRENDS_CACHE_TTL", 24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.TrendsStaleWhileRevalidate, err = getEnvDuration("TRENDS_STALE_WHILE_REVALIDATE", 24*time.Hour); err != nil {
+		return nil, err
+	}
+
+	// Shared cache
+	if cfg.CacheMemoryEntries, err = getEnvInt("CACHE_MEMORY_ENTRIES", 1000); err != nil {
+		return nil, err
+	}
+	if cfg.ChannelCacheTTL, err = getEnvDuration("CHANNEL_CACHE_TTL", time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.ResolveCacheTTL, err = getEnvDuration("RESOLVE_CACHE_TTL", 7*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.VideosCacheTTL, err = getEnvDuration("VIDEOS_CACHE_TTL", time.Hour); err != nil {
+		return nil, err
+	}
+
+	// Scheduler: refresh the watchlist daily at 05:00, spread over an hour
+	if cfg.SchedulerEnabled, err = getEnvBool("SCHEDULER_ENABLED", true); err != nil {
+		return nil, err
+	}
+	cfg.RefreshSchedule = getEnvString("REFRESH_SCHEDULE", "0 5 * * *")
+	if cfg.RefreshSpread, err = getEnvDuration("REFRESH_SPREAD", time.Hour); err != nil {
+		return nil, err
+	}
+	cfg.SchedulerTimezone = getEnvString("SCHEDULER_TIMEZONE", "UTC")
+	if cfg.SchedulerDailyQuota, err = getEnvInt("SCHEDULER_DAILY_QUOTA", 5000); err != nil {
+		return nil, err
+	}
+
+	// WebSub: off by default since the hub needs a publicly reachable callback
+	if cfg.WebSubEnabled, err = getEnvBool("WEBSUB_ENABLED", false); err != nil {
+		return nil, err
+	}
+	cfg.WebSubHubURL = getEnvString("WEBSUB_HUB_URL", "https://pubsubhubbub.appspot.com/subscribe")
+	cfg.WebSubCallbackURL = os.Getenv("WEBSUB_CALLBACK_URL")
+	cfg.WebSubSecret = os.Getenv("WEBSUB_SECRET")
+	if cfg.WebSubLease, err = getEnvDuration("WEBSUB_LEASE", 5*24*time.Hour); err != nil {
+		return nil, err
+	}
+	if cfg.WebSubRenewInterval, err = getEnvDuration("WEBSUB_RENEW_INTERVAL", time.Hour); err != nil {
+		return nil, err
+	}
+
+	// Alerts: evaluate every 5 minutes, retry failed webhooks up to 5 times from 30s apart
+	if cfg.AlertsInterval, err = getEnvDuration("ALERTS_INTERVAL", 5*time.Minute); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookTimeout, err = getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookMaxAttempts, err = getEnvInt("WEBHOOK_MAX_ATTEMPTS", 5); err != nil {
+		return nil, err
+	}
+	if cfg.WebhookRetryBackoff, err = getEnvDuration("WEBHOOK_RETRY_BACKOFF", 30*time.Second); err != nil {
+		return nil, err
+	}
+
+	// Scoring: weighted counts of views, likes and comments at 1/2/3 unless overridden
+	cfg.ScoringDefault = getEnvString("SCORING_DEFAULT", "weighted")
+	if cfg.ScoringWeightViews, err = getEnvFloat("SCORING_WEIGHT_VIEWS", 1); err != nil {
+		return nil, err
+	}
+	if cfg.ScoringWeightLikes, err = getEnvFloat("SCORING_WEIGHT_LIKES", 2); err != nil {
+		return nil, err
+	}
+	if cfg.ScoringWeightComments, err = getEnvFloat("SCORING_WEIGHT_COMMENTS", 3); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// getEnvString reads a string environment variable, falling back to a default
+func getEnvString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt reads an integer environment variable, falling back to a default
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %v", key, err)
+	}
+	return n, nil
+}
+
+// getEnvFloat reads a decimal environment variable, falling back to a default
+func getEnvFloat(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number: %v", key, err)
+	}
+	return f, nil
+}
+
+// getEnvDuration reads a duration environment variable such as "6h", falling back to a default
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration like 30m or 6h: %v", key, err)
+	}
+	return d, nil
+}
+
+// getEnvBool reads a boolean environment variable, falling back to a default
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false: %v", key, err)
+	}
+	return b, nil
+}
+
+// Validate checks if the configuration is valid
+func (c *Config) Validate() error {
+	if c.YouTubeAPIKey == "" {
+		return fmt.Errorf("%w: YOUTUBE_API_KEY environment variable is not set", ErrMissingAPIKey)
+	}
+	if c.RetentionDailyDays < 0 || c.RetentionWeeklyDays < 0 || c.RetentionMonthlyDays < 0 {
+		return fmt.Errorf("retention periods must not be negative")
+	}
+	if c.RetentionWeeklyDays > 0 && c.RetentionWeeklyDays < c.RetentionDailyDays {
+		return fmt.Errorf("RETENTION_WEEKLY_DAYS must not be shorter than RETENTION_DAILY_DAYS")
+	}
+	if c.RetentionMonthlyDays > 0 && c.RetentionMonthlyDays < c.RetentionWeeklyDays {
+		return fmt.Errorf("RETENTION_MONTHLY_DAYS must not be shorter than RETENTION_WEEKLY_DAYS")
+	}
+	if c.RefreshSpread < 0 {
+		return fmt.Errorf("REFRESH_SPREAD must not be negative")
+	}
+	if c.SchedulerDailyQuota < 0 {
+		return fmt.Errorf("SCHEDULER_DAILY_QUOTA must not be negative")
+	}
+	if _, err := time.LoadLocation(c.SchedulerTimezone); err != nil {
+		return fmt.Errorf("SCHEDULER_TIMEZONE is not a valid time zone: %v", err)
+	}
+	if c.WebhookMaxAttempts < 1 {
+		return fmt.Errorf("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.ScoringWeightViews < 0 || c.ScoringWeightLikes < 0 || c.ScoringWeightComments < 0 {
+		return fmt.Errorf("scoring weights must not be negative")
+	}
+	if c.WebSubEnabled {
+		if c.WebSubCallbackURL == "" {
+			return fmt.Errorf("WEBSUB_CALLBACK_URL is required when WEBSUB_ENABLED is true")
+		}
+		if c.WebSubLease <= 0 || c.WebSubRenewInterval <= 0 {
+			return fmt.Errorf("WEBSUB_LEASE and WEBSUB_RENEW_INTERVAL must be positive")
+		}
+	}
+	return nil
+}
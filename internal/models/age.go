@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// AgeCurve is a channel's expected views for a video of a given age,
+// Scale * ageDays^Exponent
+type AgeCurve struct {
+	// EarlyDays is the N in "views in the first N days"
+	EarlyDays int     `json:"earlyDays"`
+	Exponent  float64 `json:"exponent"`
+	Scale     float64 `json:"scale"`
+	// FromSnapshots is false when too few video snapshots exist and a default exponent is used
+	FromSnapshots bool `json:"fromSnapshots"`
+}
+
+// VideoAgePerformance is a video's performance normalized by its age
+type VideoAgePerformance struct {
+	VideoID     string    `json:"videoId"`
+	Title       string    `json:"title"`
+	PublishedAt time.Time `json:"publishedAt"`
+	AgeDays     float64   `json:"ageDays"`
+	Views       int64     `json:"views"`
+	ViewsPerDay float64   `json:"viewsPerDay"`
+	// EarlyViews is the views in the first AgeCurve.EarlyDays days
+	EarlyViews float64 `json:"earlyViews"`
+	// EarlyViewsEstimated is true when EarlyViews comes from the curve rather than snapshots
+	EarlyViewsEstimated bool    `json:"earlyViewsEstimated"`
+	ExpectedViews       float64 `json:"expectedViews"`
+	// PerformanceVsExpected is Views divided by ExpectedViews; 1 is exactly as expected
+	PerformanceVsExpected float64 `json:"performanceVsExpected"`
+}
+
+// AgeNormalizedTrend summarizes the age-normalized performance of the uploads in one period
+type AgeNormalizedTrend struct {
+	Period                      string    `json:"period"`
+	PeriodStart                 time.Time `json:"periodStart"`
+	PeriodEnd                   time.Time `json:"periodEnd"`
+	Uploads                     int       `json:"uploads"`
+	MedianViewsPerDay           float64   `json:"medianViewsPerDay"`
+	AverageEarlyViews           float64   `json:"averageEarlyViews"`
+	MedianPerformanceVsExpected float64   `json:"medianPerformanceVsExpected"`
+}
+
+// ChannelAgePerformance lists every video of a channel by performance for its age
+type ChannelAgePerformance struct {
+	ChannelID    string   `json:"channelId"`
+	ChannelTitle string   `json:"channelTitle"`
+	Curve        AgeCurve `json:"curve"`
+	// Videos are ordered by PerformanceVsExpected, highest first
+	Videos    []VideoAgePerformance `json:"videos"`
+	Timestamp time.Time             `json:"timestamp"`
+	CacheMetadata
+}
@@ -0,0 +1,338 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AlertType is the kind of event an alert rule watches for
+type AlertType string
+
+const (
+	// AlertTypeNewUpload fires for every video published after the rule was created
+	AlertTypeNewUpload AlertType = "new_upload"
+	// AlertTypeViral fires when a recent video beats a multiple of the channel's median views
+	AlertTypeViral AlertType = "viral"
+	// AlertTypeSubscriberMilestone fires when the subscriber count crosses a multiple of a step
+	AlertTypeSubscriberMilestone AlertType = "subscriber_milestone"
+	// AlertTypeLikeRatioDrop fires when recent uploads' like-to-view ratio falls well below the baseline
+	AlertTypeLikeRatioDrop AlertType = "like_ratio_drop"
+)
+
+// AlertRule is a condition on a tracked channel and the webhook its events are sent to
+type AlertRule struct {
+	ID              int64      `json:"id"`
+	ChannelID       string     `json:"channelId"`
+	Type            AlertType  `json:"type"`
+	Threshold       float64    `json:"threshold"`
+	WindowHours     int        `json:"windowHours"`
+	WebhookURL      string     `json:"webhookUrl"`
+	Secret          string     `json:"secret,omitempty"`
+	Enabled         bool       `json:"enabled"`
+	State           string     `json:"-"`
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// AlertRuleRequest is the body accepted when creating or replacing an alert rule.
+// Threshold and WindowHours fall back to per-type defaults when zero.
+type AlertRuleRequest struct {
+	ChannelID   string    `json:"channelId" binding:"required"`
+	Type        AlertType `json:"type" binding:"required"`
+	Threshold   float64   `json:"threshold"`
+	WindowHours int       `json:"windowHours"`
+	WebhookURL  string    `json:"webhookUrl" binding:"required"`
+	Secret      string    `json:"secret"`
+	Enabled     *bool     `json:"enabled"`
+}
+
+// DeliveryStatus is the state of a webhook delivery
+type DeliveryStatus string
+
+const (
+	DeliveryStatusPending   DeliveryStatus = "pending"
+	DeliveryStatusDelivered DeliveryStatus = "delivered"
+	DeliveryStatusFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent, or being retried, to a rule's webhook
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	RuleID         int64           `json:"ruleId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"responseStatus,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+const alertRuleColumns = `id, channel_id, type, threshold, window_hours, webhook_url, secret, enabled, state,
+			last_triggered_at, created_at, updated_at`
+
+const deliveryColumns = `id, rule_id, event_type, payload, status, attempts, response_status, error,
+			next_attempt_at, created_at, delivered_at`
+
+// CreateAlertRule stores a new rule and sets its ID
+func (d *Database) CreateAlertRule(rule *AlertRule) error {
+	sql := `INSERT INTO alert_rules (channel_id, type, threshold, window_hours, webhook_url, secret, enabled)
+			VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	args := []interface{}{rule.ChannelID, string(rule.Type), rule.Threshold, rule.WindowHours, rule.WebhookURL, rule.Secret, boolToInt(rule.Enabled)}
+	result, err := d.selectArray(sql, args)
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return fmt.Errorf("failed to create alert rule: no id returned")
+	}
+	rule.ID = result.GetInt64Value_(0, 0)
+	return nil
+}
+
+// UpdateAlertRule replaces a rule's definition and clears its evaluation state
+func (d *Database) UpdateAlertRule(rule *AlertRule) error {
+	sql := `UPDATE alert_rules
+			SET channel_id = ?, type = ?, threshold = ?, window_hours = ?, webhook_url = ?, secret = ?,
+				enabled = ?, state = '', updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?`
+
+	args := []interface{}{rule.ChannelID, string(rule.Type), rule.Threshold, rule.WindowHours, rule.WebhookURL, rule.Secret, boolToInt(rule.Enabled), rule.ID}
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to update alert rule: %v", err)
+	}
+	return nil
+}
+
+// SaveAlertState stores a rule's evaluation state, marking it triggered if it fired
+func (d *Database) SaveAlertState(id int64, state string, triggered bool) error {
+	sql := `UPDATE alert_rules SET state = ? WHERE id = ?`
+	if triggered {
+		sql = `UPDATE alert_rules SET state = ?, last_triggered_at = CURRENT_TIMESTAMP WHERE id = ?`
+	}
+
+	if err := d.executeArray(sql, []interface{}{state, id}); err != nil {
+		return fmt.Errorf("failed to save alert state: %v", err)
+	}
+	return nil
+}
+
+// DeleteAlertRule removes a rule and its delivery log, reporting whether it existed
+func (d *Database) DeleteAlertRule(id int64) (bool, error) {
+	existing, err := d.GetAlertRule(id)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil {
+		return false, nil
+	}
+
+	if err := d.executeArray(`DELETE FROM webhook_deliveries WHERE rule_id = ?`, []interface{}{id}); err != nil {
+		return false, fmt.Errorf("failed to delete webhook deliveries: %v", err)
+	}
+	if err := d.executeArray(`DELETE FROM alert_rules WHERE id = ?`, []interface{}{id}); err != nil {
+		return false, fmt.Errorf("failed to delete alert rule: %v", err)
+	}
+	return true, nil
+}
+
+// GetAlertRule returns a rule by ID, or nil if it does not exist
+func (d *Database) GetAlertRule(id int64) (*AlertRule, error) {
+	sql := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE id = ?`
+
+	result, err := d.selectArray(sql, []interface{}{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+	return parseAlertRuleRow(result.GetStringValue_, result.GetInt64Value_, result.GetFloat64Value_, 0)
+}
+
+// GetAlertRules returns every rule, optionally only those of one channel or only enabled ones
+func (d *Database) GetAlertRules(channelID string, enabledOnly bool) ([]*AlertRule, error) {
+	sql := `SELECT ` + alertRuleColumns + ` FROM alert_rules WHERE 1 = 1`
+	args := []interface{}{}
+	if channelID != "" {
+		sql += ` AND channel_id = ?`
+		args = append(args, channelID)
+	}
+	if enabledOnly {
+		sql += ` AND enabled = 1`
+	}
+	sql += ` ORDER BY id`
+
+	result, err := d.selectArray(sql, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rules: %v", err)
+	}
+
+	rules := make([]*AlertRule, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		rule, err := parseAlertRuleRow(result.GetStringValue_, result.GetInt64Value_, result.GetFloat64Value_, r)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// CreateDelivery stores a pending delivery and sets its ID
+func (d *Database) CreateDelivery(delivery *WebhookDelivery) error {
+	sql := `INSERT INTO webhook_deliveries (rule_id, event_type, payload, status, next_attempt_at)
+			VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP) RETURNING id`
+
+	args := []interface{}{delivery.RuleID, delivery.EventType, string(delivery.Payload), string(DeliveryStatusPending)}
+	result, err := d.selectArray(sql, args)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return fmt.Errorf("failed to create webhook delivery: no id returned")
+	}
+	delivery.ID = result.GetInt64Value_(0, 0)
+	delivery.Status = DeliveryStatusPending
+	return nil
+}
+
+// RecordDeliveryAttempt stores the outcome of one attempt. A pending delivery is retried
+// once nextAttemptAt has passed.
+func (d *Database) RecordDeliveryAttempt(id int64, status DeliveryStatus, responseStatus int, attemptErr string, nextAttemptAt time.Time) error {
+	sql := `UPDATE webhook_deliveries
+			SET status = ?, attempts = attempts + 1, response_status = ?, error = ?, next_attempt_at = ?,
+				delivered_at = CASE WHEN ? = 'delivered' THEN CURRENT_TIMESTAMP ELSE delivered_at END
+			WHERE id = ?`
+
+	var next interface{}
+	if status == DeliveryStatusPending {
+		next = nextAttemptAt.UTC().Format(timestampLayout)
+	}
+	args := []interface{}{string(status), responseStatus, attemptErr, next, string(status), id}
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to record webhook delivery attempt: %v", err)
+	}
+	return nil
+}
+
+// GetDelivery returns a delivery by ID, or nil if it does not exist
+func (d *Database) GetDelivery(id int64) (*WebhookDelivery, error) {
+	sql := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = ?`
+
+	result, err := d.selectArray(sql, []interface{}{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+	return parseDeliveryRow(result.GetStringValue_, result.GetInt64Value_, 0)
+}
+
+// GetDeliveries returns a page of a rule's deliveries, newest first
+func (d *Database) GetDeliveries(ruleID int64, limit, offset int) ([]*WebhookDelivery, error) {
+	sql := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+			WHERE rule_id = ? ORDER BY id DESC LIMIT ? OFFSET ?`
+
+	return d.queryDeliveries(sql, []interface{}{ruleID, limit, offset})
+}
+
+// GetDueDeliveries returns pending deliveries whose next attempt is due
+func (d *Database) GetDueDeliveries(limit int) ([]*WebhookDelivery, error) {
+	sql := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY next_attempt_at LIMIT ?`
+
+	return d.queryDeliveries(sql, []interface{}{string(DeliveryStatusPending), limit})
+}
+
+func (d *Database) queryDeliveries(sql string, args []interface{}) ([]*WebhookDelivery, error) {
+	result, err := d.selectArray(sql, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %v", err)
+	}
+
+	deliveries := make([]*WebhookDelivery, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		delivery, err := parseDeliveryRow(result.GetStringValue_, result.GetInt64Value_, r)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+func parseAlertRuleRow(str func(row, column uint64) string, num func(row, column uint64) int64, float func(row, column uint64) float64, r uint64) (*AlertRule, error) {
+	createdAt, err := time.Parse(timestampLayout, str(r, 10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %v", err)
+	}
+	updatedAt, err := time.Parse(timestampLayout, str(r, 11))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %v", err)
+	}
+
+	rule := &AlertRule{
+		ID:          num(r, 0),
+		ChannelID:   str(r, 1),
+		Type:        AlertType(str(r, 2)),
+		Threshold:   float(r, 3),
+		WindowHours: int(num(r, 4)),
+		WebhookURL:  str(r, 5),
+		Secret:      str(r, 6),
+		Enabled:     num(r, 7) == 1,
+		State:       str(r, 8),
+		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+	}
+	if value := str(r, 9); value != "" {
+		if triggeredAt, err := time.Parse(timestampLayout, value); err == nil {
+			rule.LastTriggeredAt = &triggeredAt
+		}
+	}
+	return rule, nil
+}
+
+func parseDeliveryRow(str func(row, column uint64) string, num func(row, column uint64) int64, r uint64) (*WebhookDelivery, error) {
+	createdAt, err := time.Parse(timestampLayout, str(r, 9))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %v", err)
+	}
+
+	delivery := &WebhookDelivery{
+		ID:             num(r, 0),
+		RuleID:         num(r, 1),
+		EventType:      str(r, 2),
+		Payload:        json.RawMessage(str(r, 3)),
+		Status:         DeliveryStatus(str(r, 4)),
+		Attempts:       int(num(r, 5)),
+		ResponseStatus: int(num(r, 6)),
+		Error:          str(r, 7),
+		CreatedAt:      createdAt,
+	}
+	if value := str(r, 8); value != "" {
+		if nextAt, err := time.Parse(timestampLayout, value); err == nil {
+			delivery.NextAttemptAt = &nextAt
+		}
+	}
+	if value := str(r, 10); value != "" {
+		if deliveredAt, err := time.Parse(timestampLayout, value); err == nil {
+			delivery.DeliveredAt = &deliveredAt
+		}
+	}
+	return delivery, nil
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -1,91 +1,156 @@
-package models
-
-import "time"
-
-// ChannelAnalytics represents engagement analytics for a channel
-type ChannelAnalytics struct {
-	ChannelID          string    `json:"channelId"`
-	ChannelTitle       string    `json:"channelTitle"`
-	ChannelName        string    `json:"channelName"`
-	SubscriberCount    int64     `json:"subscriberCount"`
-	ViewCount          int64     `json:"viewCount"`
-	VideoCount         int64     `json:"videoCount"`
-	TotalVideos        int       `json:"totalVideos"`
-	AverageViews       float64   `json:"averageViews"`
-	LikeToViewRatio    float64   `json:"likeToViewRatio"`
-	CommentToViewRatio float64   `json:"commentToViewRatio"`
-	TopEngagingVideos  []Video   `json:"topEngagingVideos"`
-	TimeRange          TimeRange `json:"timeRange"`
-	Timestamp          time.Time `json:"timestamp"`
-}
-
-// TimeRange represents the time period for analytics
-type TimeRange struct {
-	StartDate string `json:"startDate"`
-	EndDate   string `json:"endDate"`
-}
-
-// EngagementScore calculates the engagement score for a video
-// This is a weighted combination of views, likes, and comments
-func (v *Video) EngagementScore() float64 {
-	if v.Views == 0 {
-		return 0
-	}
-
-	// Weight factors (can be adjusted)
-	const (
-		viewWeight    = 1.0
-		likeWeight    = 2.0
-		commentWeight = 3.0
-	)
-
-	// Calculate weighted score
-	score := (viewWeight * float64(v.Views)) +
-		(likeWeight * float64(v.Likes)) +
-		(commentWeight * float64(v.Comments))
-
-	return score
-}
-
-// ChannelTrends represents trends and time series analytics for a channel
-// Used for /channel/:id/trends endpoint
-
-type VideoPerformancePoint struct {
-	UploadDate      time.Time `json:"uploadDate"`
-	Views           int64     `json:"views"`
-	Likes           int64     `json:"likes"`
-	Comments        int64     `json:"comments"`
-	LikesToViews    float64   `json:"likesToViews"`
-	CommentsToViews float64   `json:"commentsToViews"`
-}
-
-type RollingAverage struct {
-	UploadIndex  int     `json:"uploadIndex"`
-	AverageViews float64 `json:"averageViews"`
-}
-
-type UploadFrequency struct {
-	Period string `json:"period"` // e.g. "week", "month"
-	Count  int    `json:"count"`
-}
-
-type EngagementTrend struct {
-	Period          string  `json:"period"`
-	AverageViews    float64 `json:"averageViews"`
-	AverageLikes    float64 `json:"averageLikes"`
-	AverageComments float64 `json:"averageComments"`
-}
-
-type ChannelTrends struct {
-	ChannelID               string                  `json:"channelId"`
-	ChannelTitle            string                  `json:"channelTitle"`
-	ChannelName             string                  `json:"channelName"`
-	TrendingVideos          []Video                 `json:"trendingVideos"`
-	PerformanceOverTime     []VideoPerformancePoint `json:"performanceOverTime"`
-	RollingAverages         []RollingAverage        `json:"rollingAverages"`
-	UploadFrequencyWeekly   []UploadFrequency       `json:"uploadFrequencyWeekly"`
-	UploadFrequencyMonthly  []UploadFrequency       `json:"uploadFrequencyMonthly"`
-	EngagementTrendsWeekly  []EngagementTrend       `json:"engagementTrendsWeekly"`
-	EngagementTrendsMonthly []EngagementTrend       `json:"engagementTrendsMonthly"`
-	Timestamp               time.Time               `json:"timestamp"`
-}
+package models
+
+import "time"
+
+// ChannelAnalytics represents engagement analytics for a channel
+type ChannelAnalytics struct {
+	ChannelID          string           `json:"channelId"`
+	ChannelTitle       string           `json:"channelTitle"`
+	ChannelName        string           `json:"channelName"`
+	SubscriberCount    int64            `json:"subscriberCount"`
+	ViewCount          int64            `json:"viewCount"`
+	VideoCount         int64            `json:"videoCount"`
+	TotalVideos        int              `json:"totalVideos"`
+	AverageViews       float64          `json:"averageViews"`
+	LikeToViewRatio    float64          `json:"likeToViewRatio"`
+	CommentToViewRatio float64          `json:"commentToViewRatio"`
+	TopEngagingVideos  []Video          `json:"topEngagingVideos"`
+	TimeRange          TimeRange        `json:"timeRange"`
+	DurationBuckets    []DurationBucket `json:"durationBuckets,omitempty"`
+	Scoring            *ScoringModel    `json:"scoring,omitempty"`
+	Timestamp          time.Time        `json:"timestamp"`
+	CacheMetadata
+}
+
+// DurationBucket summarizes the performance of videos within a range of lengths
+type DurationBucket struct {
+	Bucket     string `json:"bucket"`
+	MinSeconds int    `json:"minSeconds"`
+	// MaxSeconds is exclusive; 0 means the bucket has no upper bound
+	MaxSeconds         int                  `json:"maxSeconds"`
+	Videos             int                  `json:"videos"`
+	MedianViews        float64              `json:"medianViews"`
+	LikeToViewRatio    float64              `json:"likeToViewRatio"`
+	CommentToViewRatio float64              `json:"commentToViewRatio"`
+	Trend              []DurationTrendPoint `json:"trend"`
+}
+
+// DurationTrendPoint is a duration bucket's uploads in one month
+type DurationTrendPoint struct {
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Videos      int       `json:"videos"`
+	MedianViews float64   `json:"medianViews"`
+}
+
+// ScoringWeights weigh a video's views, likes and comments when scoring engagement
+type ScoringWeights struct {
+	Views    float64 `json:"views"`
+	Likes    float64 `json:"likes"`
+	Comments float64 `json:"comments"`
+}
+
+// ScoringModel describes how the videos in a response were ranked
+type ScoringModel struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Weights     ScoringWeights `json:"weights"`
+}
+
+// CacheMetadata describes where a response came from and how long it stays fresh
+type CacheMetadata struct {
+	FetchedAt time.Time `json:"fetchedAt"`
+	FromCache bool      `json:"fromCache"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Stale     bool      `json:"stale,omitempty"`
+}
+
+// TimeRange represents the time period for analytics
+type TimeRange struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+// EngagementScore calculates the engagement score for a video
+// This is a weighted combination of views, likes, and comments
+func (v *Video) EngagementScore() float64 {
+	if v.Views == 0 {
+		return 0
+	}
+
+	// Weight factors (can be adjusted)
+	const (
+		viewWeight    = 1.0
+		likeWeight    = 2.0
+		commentWeight = 3.0
+	)
+
+	// Calculate weighted score
+	score := (viewWeight * float64(v.Views)) +
+		(likeWeight * float64(v.Likes)) +
+		(commentWeight * float64(v.Comments))
+
+	return score
+}
+
+// ChannelTrends represents trends and time series analytics for a channel
+// Used for /channel/:id/trends endpoint
+
+type VideoPerformancePoint struct {
+	UploadDate      time.Time `json:"uploadDate"`
+	Views           int64     `json:"views"`
+	Likes           int64     `json:"likes"`
+	Comments        int64     `json:"comments"`
+	LikesToViews    float64   `json:"likesToViews"`
+	CommentsToViews float64   `json:"commentsToViews"`
+}
+
+type RollingAverage struct {
+	UploadIndex  int     `json:"uploadIndex"`
+	AverageViews float64 `json:"averageViews"`
+}
+
+type UploadFrequency struct {
+	Period string `json:"period"` // e.g. "2024-W05", "2024-01"
+	// PeriodStart is inclusive and PeriodEnd exclusive
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	Count       int       `json:"count"`
+}
+
+type EngagementTrend struct {
+	Period          string    `json:"period"`
+	PeriodStart     time.Time `json:"periodStart"`
+	PeriodEnd       time.Time `json:"periodEnd"`
+	Uploads         int       `json:"uploads"`
+	AverageViews    float64   `json:"averageViews"`
+	AverageLikes    float64   `json:"averageLikes"`
+	AverageComments float64   `json:"averageComments"`
+}
+
+type ChannelTrends struct {
+	ChannelID               string                  `json:"channelId"`
+	ChannelTitle            string                  `json:"channelTitle"`
+	ChannelName             string                  `json:"channelName"`
+	TrendingVideos          []Video                 `json:"trendingVideos"`
+	PerformanceOverTime     []VideoPerformancePoint `json:"performanceOverTime"`
+	RollingAverages         []RollingAverage        `json:"rollingAverages"`
+	UploadFrequencyWeekly   []UploadFrequency       `json:"uploadFrequencyWeekly"`
+	UploadFrequencyMonthly  []UploadFrequency       `json:"uploadFrequencyMonthly"`
+	EngagementTrendsWeekly  []EngagementTrend       `json:"engagementTrendsWeekly"`
+	EngagementTrendsMonthly []EngagementTrend       `json:"engagementTrendsMonthly"`
+	// UploadFrequency and EngagementTrends use the requested Granularity and Timezone
+	Granularity      string            `json:"granularity"`
+	Timezone         string            `json:"timezone"`
+	UploadFrequency  []UploadFrequency `json:"uploadFrequency"`
+	EngagementTrends []EngagementTrend `json:"engagementTrends"`
+	AgeCurve         *AgeCurve         `json:"ageCurve,omitempty"`
+	// AgeNormalizedVideos are the videos furthest above the views expected for their age
+	AgeNormalizedVideos  []VideoAgePerformance `json:"ageNormalizedVideos"`
+	AgeNormalizedWeekly  []AgeNormalizedTrend  `json:"ageNormalizedWeekly"`
+	AgeNormalizedMonthly []AgeNormalizedTrend  `json:"ageNormalizedMonthly"`
+	Scoring              *ScoringModel         `json:"scoring,omitempty"`
+	Timestamp            time.Time             `json:"timestamp"`
+	CacheMetadata
+}
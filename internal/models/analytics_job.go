@@ -0,0 +1,193 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	sqlitecloud "github.com/sqlitecloud/sqlitecloud-go"
+)
+
+// AnalyticsJobProgress is how far an analytics job's crawl has got
+type AnalyticsJobProgress struct {
+	VideosFetched int64   `json:"videosFetched"`
+	VideosTotal   int64   `json:"videosTotal"`
+	Percent       float64 `json:"percent"`
+}
+
+// AnalyticsJob is an asynchronous analytics computation for one channel
+type AnalyticsJob struct {
+	ID         string               `json:"id"`
+	ChannelID  string               `json:"channelId"`
+	Status     JobRunStatus         `json:"status"`
+	Progress   AnalyticsJobProgress `json:"progress"`
+	Result     json.RawMessage      `json:"result,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	CreatedAt  time.Time            `json:"createdAt"`
+	UpdatedAt  time.Time            `json:"updatedAt"`
+	FinishedAt *time.Time           `json:"finishedAt,omitempty"`
+}
+
+// analyticsJobColumns is the column list parseAnalyticsJobRow expects
+const analyticsJobColumns = `id, channel_id, status, videos_fetched, videos_total, result, error,
+			created_at, updated_at, finished_at`
+
+// CreateAnalyticsJob stores a new queued job unless the channel already has a queued or
+// running one. It returns the channel's active job and whether it was created by this call.
+// The check and the insert run in one transaction so concurrent calls cannot both create a job.
+func (d *Database) CreateAnalyticsJob(id, channelID string) (*AnalyticsJob, bool, error) {
+	var job *AnalyticsJob
+	var created bool
+	err := d.transaction(func(exec func(string, []interface{}) error, query func(string, []interface{}) (*sqlitecloud.Result, error)) error {
+		result, err := query(`SELECT `+analyticsJobColumns+` FROM analytics_jobs
+			WHERE channel_id = ? AND status IN (?, ?)
+			ORDER BY created_at DESC LIMIT 1`,
+			[]interface{}{channelID, string(JobRunStatusQueued), string(JobRunStatusRunning)})
+		if err != nil {
+			return fmt.Errorf("failed to get active analytics job: %v", err)
+		}
+		if result.GetNumberOfRows() > 0 {
+			job, err = parseAnalyticsJobRow(result, 0)
+			return err
+		}
+
+		if err := exec(`INSERT INTO analytics_jobs (id, channel_id, status) VALUES (?, ?, ?)`,
+			[]interface{}{id, channelID, string(JobRunStatusQueued)}); err != nil {
+			return fmt.Errorf("failed to create analytics job: %v", err)
+		}
+		result, err = query(`SELECT `+analyticsJobColumns+` FROM analytics_jobs WHERE id = ?`, []interface{}{id})
+		if err != nil {
+			return fmt.Errorf("failed to get analytics job: %v", err)
+		}
+		if result.GetNumberOfRows() == 0 {
+			return fmt.Errorf("analytics job %s was not stored", id)
+		}
+		job, err = parseAnalyticsJobRow(result, 0)
+		created = err == nil
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return job, created, nil
+}
+
+// GetAnalyticsJob retrieves a job by ID, returning nil if it does not exist
+func (d *Database) GetAnalyticsJob(id string) (*AnalyticsJob, error) {
+	sql := `SELECT ` + analyticsJobColumns + ` FROM analytics_jobs WHERE id = ?`
+
+	result, err := d.selectArray(sql, []interface{}{id})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get analytics job: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+	return parseAnalyticsJobRow(result, 0)
+}
+
+// GetUnfinishedAnalyticsJobs returns every queued or running job, oldest first
+func (d *Database) GetUnfinishedAnalyticsJobs() ([]*AnalyticsJob, error) {
+	sql := `SELECT ` + analyticsJobColumns + ` FROM analytics_jobs
+			WHERE status IN (?, ?)
+			ORDER BY created_at`
+
+	result, err := d.selectArray(sql, []interface{}{string(JobRunStatusQueued), string(JobRunStatusRunning)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unfinished analytics jobs: %v", err)
+	}
+
+	jobs := make([]*AnalyticsJob, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		job, err := parseAnalyticsJobRow(result, r)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// StartAnalyticsJob marks a job as running and resets its progress
+func (d *Database) StartAnalyticsJob(id string) error {
+	sql := `UPDATE analytics_jobs
+			SET status = ?, videos_fetched = 0, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?`
+
+	if err := d.executeArray(sql, []interface{}{string(JobRunStatusRunning), id}); err != nil {
+		return fmt.Errorf("failed to start analytics job: %v", err)
+	}
+	return nil
+}
+
+// UpdateAnalyticsJobProgress records how many videos a job has fetched so far
+func (d *Database) UpdateAnalyticsJobProgress(id string, fetched, total int64) error {
+	sql := `UPDATE analytics_jobs
+			SET videos_fetched = ?, videos_total = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?`
+
+	if err := d.executeArray(sql, []interface{}{fetched, total, id}); err != nil {
+		return fmt.Errorf("failed to update analytics job progress: %v", err)
+	}
+	return nil
+}
+
+// FinishAnalyticsJob stores the outcome of a job: its result on success or the error on failure
+func (d *Database) FinishAnalyticsJob(id string, result []byte, jobErr error) error {
+	status, errMsg := JobRunStatusSucceeded, ""
+	if jobErr != nil {
+		status, errMsg = JobRunStatusFailed, jobErr.Error()
+	}
+
+	sql := `UPDATE analytics_jobs
+			SET status = ?, result = ?, error = ?, updated_at = CURRENT_TIMESTAMP, finished_at = CURRENT_TIMESTAMP
+			WHERE id = ?`
+
+	if err := d.executeArray(sql, []interface{}{string(status), string(result), errMsg, id}); err != nil {
+		return fmt.Errorf("failed to finish analytics job: %v", err)
+	}
+	return nil
+}
+
+// parseAnalyticsJobRow builds a job from a row selected with analyticsJobColumns
+func parseAnalyticsJobRow(result *sqlitecloud.Result, r uint64) (*AnalyticsJob, error) {
+	createdAt, err := time.Parse(timestampLayout, result.GetStringValue_(r, 7))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %v", err)
+	}
+	updatedAt, err := time.Parse(timestampLayout, result.GetStringValue_(r, 8))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %v", err)
+	}
+
+	job := &AnalyticsJob{
+		ID:        result.GetStringValue_(r, 0),
+		ChannelID: result.GetStringValue_(r, 1),
+		Status:    JobRunStatus(result.GetStringValue_(r, 2)),
+		Progress: AnalyticsJobProgress{
+			VideosFetched: result.GetInt64Value_(r, 3),
+			VideosTotal:   result.GetInt64Value_(r, 4),
+		},
+		Error:     result.GetStringValue_(r, 6),
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+	if job.Progress.VideosTotal > 0 {
+		job.Progress.Percent = float64(job.Progress.VideosFetched) / float64(job.Progress.VideosTotal) * 100
+		if job.Progress.Percent > 100 {
+			job.Progress.Percent = 100
+		}
+	}
+	if job.Status == JobRunStatusSucceeded {
+		job.Progress.Percent = 100
+	}
+	if data := result.GetStringValue_(r, 5); data != "" {
+		job.Result = json.RawMessage(data)
+	}
+	if finished := result.GetStringValue_(r, 9); finished != "" {
+		if finishedAt, err := time.Parse(timestampLayout, finished); err == nil {
+			job.FinishedAt = &finishedAt
+		}
+	}
+	return job, nil
+}
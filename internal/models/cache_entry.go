@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	sqlitecloud "github.com/sqlitecloud/sqlitecloud-go"
+)
+
+// CacheEntry is a row in the cache_entries table backing the shared cache
+type CacheEntry struct {
+	Key       string
+	Resource  string
+	Value     []byte
+	StoredAt  time.Time
+	ExpiresAt time.Time
+}
+
+// GetCacheEntry retrieves a cache entry by key, returning nil if it does not exist
+func (d *Database) GetCacheEntry(key string) (*CacheEntry, error) {
+	sql := `SELECT cache_key, resource, value, stored_at, expires_at
+			FROM cache_entries WHERE cache_key = ?`
+
+	result, err := d.selectArray(sql, []interface{}{key})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cache entry: %v", err)
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+
+	storedAt, err := time.Parse(timestampLayout, result.GetStringValue_(0, 3))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse stored_at: %v", err)
+	}
+
+	expiresAt, err := time.Parse(timestampLayout, result.GetStringValue_(0, 4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse expires_at: %v", err)
+	}
+
+	return &CacheEntry{
+		Key:       result.GetStringValue_(0, 0),
+		Resource:  result.GetStringValue_(0, 1),
+		Value:     []byte(result.GetStringValue_(0, 2)),
+		StoredAt:  storedAt,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// SetCacheEntry inserts or replaces a cache entry
+func (d *Database) SetCacheEntry(entry *CacheEntry) error {
+	sql := `INSERT INTO cache_entries (cache_key, resource, value, stored_at, expires_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(cache_key) DO UPDATE SET
+				resource = excluded.resource,
+				value = excluded.value,
+				stored_at = excluded.stored_at,
+				expires_at = excluded.expires_at`
+
+	args := []interface{}{
+		entry.Key,
+		entry.Resource,
+		string(entry.Value),
+		entry.StoredAt.UTC().Format(timestampLayout),
+		entry.ExpiresAt.UTC().Format(timestampLayout),
+	}
+
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to set cache entry: %v", err)
+	}
+	return nil
+}
+
+// DeleteCacheEntry removes a cache entry
+func (d *Database) DeleteCacheEntry(key string) error {
+	sql := `DELETE FROM cache_entries WHERE cache_key = ?`
+	if err := d.executeArray(sql, []interface{}{key}); err != nil {
+		return fmt.Errorf("failed to delete cache entry: %v", err)
+	}
+	return nil
+}
+
+// DeleteExpiredCacheEntries removes entries that expired before now and returns how many there were
+func (d *Database) DeleteExpiredCacheEntries(now time.Time) (int64, error) {
+	var deleted int64
+	cutoff := []interface{}{now.UTC().Format(timestampLayout)}
+	err := d.transaction(func(exec func(string, []interface{}) error, query func(string, []interface{}) (*sqlitecloud.Result, error)) error {
+		result, err := query(`SELECT COUNT(*) FROM cache_entries WHERE expires_at < ?`, cutoff)
+		if err != nil {
+			return fmt.Errorf("failed to count expired cache entries: %v", err)
+		}
+		if result.GetNumberOfRows() > 0 {
+			deleted = result.GetInt64Value_(0, 0)
+		}
+		if err := exec(`DELETE FROM cache_entries WHERE expires_at < ?`, cutoff); err != nil {
+			return fmt.Errorf("failed to delete expired cache entries: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// UploadGap is the time between two consecutive uploads
+type UploadGap struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	Days float64   `json:"days"`
+}
+
+// UploadStreak is a run of consecutive ISO weeks with at least one upload each
+type UploadStreak struct {
+	Weeks     int    `json:"weeks"`
+	StartWeek string `json:"startWeek,omitempty"`
+	EndWeek   string `json:"endWeek,omitempty"`
+}
+
+// ScheduleChange marks an upload after which the channel's typical gap changed
+type ScheduleChange struct {
+	Date                time.Time `json:"date"`
+	MedianGapDaysBefore float64   `json:"medianGapDaysBefore"`
+	MedianGapDaysAfter  float64   `json:"medianGapDaysAfter"`
+}
+
+// ChannelCadence describes how regularly a channel uploads
+type ChannelCadence struct {
+	ChannelID     string     `json:"channelId"`
+	ChannelTitle  string     `json:"channelTitle"`
+	TotalVideos   int        `json:"totalVideos"`
+	FirstUpload   *time.Time `json:"firstUpload,omitempty"`
+	LastUpload    *time.Time `json:"lastUpload,omitempty"`
+	MeanGapDays   float64    `json:"meanGapDays"`
+	MedianGapDays float64    `json:"medianGapDays"`
+	// LongestHiatus is omitted for channels with fewer than two uploads
+	LongestHiatus *UploadGap `json:"longestHiatus,omitempty"`
+	// CurrentStreak counts weeks up to this week, or last week while this week has no upload yet
+	CurrentStreak UploadStreak `json:"currentStreak"`
+	LongestStreak UploadStreak `json:"longestStreak"`
+	// ConsistencyScore runs from 0 to 100, 100 meaning perfectly even gaps
+	ConsistencyScore float64          `json:"consistencyScore"`
+	ScheduleChanges  []ScheduleChange `json:"scheduleChanges"`
+	// NextExpectedUpload is the last upload plus the median gap since the latest schedule change
+	NextExpectedUpload *time.Time `json:"nextExpectedUpload,omitempty"`
+	Overdue            bool       `json:"overdue"`
+	Timestamp          time.Time  `json:"timestamp"`
+	CacheMetadata
+}
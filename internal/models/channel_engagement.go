@@ -1,210 +1,302 @@
-package models
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"time"
-)
-
-// EngagementType represents the type of engagement data
-type EngagementType string
-
-const (
-	EngagementTypeAnalytics EngagementType = "analytics"
-	EngagementTypeTrends    EngagementType = "trends"
-)
-
-// ChannelEngagement represents a record in the channel_engagement table
-type ChannelEngagement struct {
-	ID             int64           `json:"id"`
-	ChannelID      string          `json:"channel_id"`
-	EngagementType EngagementType  `json:"engagement_type"`
-	CreateDate     time.Time       `json:"create_date"`
-	UpdateDate     time.Time       `json:"update_date"`
-	JSONResponse   json.RawMessage `json:"json_response"`
-}
-
-// CreateChannelEngagementTable creates the channel_engagement table if it doesn't exist
-func (d *Database) CreateChannelEngagementTable() error {
-	sql := `
-	CREATE TABLE IF NOT EXISTS channel_engagement (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		channel_id TEXT NOT NULL,
-		engagement_type TEXT NOT NULL CHECK(engagement_type IN ('analytics', 'trends')),
-		create_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		update_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-		json_response JSON NOT NULL,
-		UNIQUE(channel_id, engagement_type)
-	);
-	CREATE INDEX IF NOT EXISTS idx_channel_engagement_channel_id ON channel_engagement(channel_id);
-	CREATE INDEX IF NOT EXISTS idx_channel_engagement_type ON channel_engagement(engagement_type);
-	`
-	return d.executeSQL(sql)
-}
-
-// StoreEngagement stores a new engagement record
-func (d *Database) StoreEngagement(engagement *ChannelEngagement) error {
-	log.Printf("Storing engagement for channel %s, type %s", engagement.ChannelID, engagement.EngagementType)
-
-	// First check if a record exists with both channel_id AND engagement_type
-	checkSQL := `SELECT COUNT(*) FROM channel_engagement 
-				 WHERE channel_id = ? AND engagement_type = ?`
-
-	result, err := d.db.SelectArray(checkSQL, []interface{}{engagement.ChannelID, string(engagement.EngagementType)})
-	if err != nil {
-		log.Printf("Error checking existing record: %v", err)
-		return fmt.Errorf("failed to check existing record: %v", err)
-	}
-
-	count, err := result.GetInt64Value(0, 0)
-	if err != nil {
-		log.Printf("Error getting count: %v", err)
-		return fmt.Errorf("failed to get count: %v", err)
-	}
-
-	// Execute INSERT or UPDATE based on whether record exists
-	var sql string
-	var args []interface{}
-
-	if count > 0 {
-		log.Printf("Updating existing record for channel %s and type %s", engagement.ChannelID, engagement.EngagementType)
-		sql = `UPDATE channel_engagement 
-			   SET json_response = ?, update_date = CURRENT_TIMESTAMP
-			   WHERE channel_id = ? AND engagement_type = ?`
-		args = []interface{}{string(engagement.JSONResponse), engagement.ChannelID, string(engagement.EngagementType)}
-	} else {
-		log.Printf("Inserting new record for channel %s and type %s", engagement.ChannelID, engagement.EngagementType)
-		sql = `INSERT INTO channel_engagement 
-			   (channel_id, engagement_type, json_response) 
-			   VALUES (?, ?, ?)`
-		args = []interface{}{engagement.ChannelID, string(engagement.EngagementType), string(engagement.JSONResponse)}
-	}
-
-	err = d.db.ExecuteArray(sql, args)
-	if err != nil {
-		log.Printf("Error storing engagement: %v", err)
-		return fmt.Errorf("failed to store engagement: %v", err)
-	}
-
-	log.Printf("Successfully stored engagement")
-	return nil
-}
-
-// GetLatestEngagement retrieves the latest engagement record for a channel and type
-func (d *Database) GetLatestEngagement(channelID string, engagementType EngagementType) (*ChannelEngagement, error) {
-	sql := `SELECT id, channel_id, engagement_type, create_date, update_date, json_response 
-			FROM channel_engagement 
-			WHERE channel_id = ? AND engagement_type = ?
-			ORDER BY create_date DESC LIMIT 1`
-
-	result, err := d.db.SelectArray(sql, []interface{}{channelID, string(engagementType)})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get latest engagement: %v", err)
-	}
-
-	if result.GetNumberOfRows() == 0 {
-		return nil, nil // No record found
-	}
-
-	// Parse the fields
-	id, err := result.GetInt64Value(0, 0)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse id: %v", err)
-	}
-
-	channelIDValue, err := result.GetStringValue(0, 1)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse channel_id: %v", err)
-	}
-
-	engagementTypeValue, err := result.GetStringValue(0, 2)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse engagement_type: %v", err)
-	}
-
-	createDateStr, err := result.GetStringValue(0, 3)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse create_date: %v", err)
-	}
-
-	updateDateStr, err := result.GetStringValue(0, 4)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse update_date: %v", err)
-	}
-
-	jsonResponse, err := result.GetStringValue(0, 5)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse json_response: %v", err)
-	}
-
-	createDate, err := time.Parse("2006-01-02 15:04:05", createDateStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse create_date: %v", err)
-	}
-
-	updateDate, err := time.Parse("2006-01-02 15:04:05", updateDateStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse update_date: %v", err)
-	}
-
-	engagement := &ChannelEngagement{
-		ID:             id,
-		ChannelID:      channelIDValue,
-		EngagementType: EngagementType(engagementTypeValue),
-		CreateDate:     createDate,
-		UpdateDate:     updateDate,
-		JSONResponse:   json.RawMessage(jsonResponse),
-	}
-
-	return engagement, nil
-}
-
-// UpdateEngagement updates an existing engagement record
-func (d *Database) UpdateEngagement(engagement *ChannelEngagement) error {
-	sql := `UPDATE channel_engagement 
-			SET json_response = ?, update_date = CURRENT_TIMESTAMP 
-			WHERE channel_id = ? AND engagement_type = ?`
-
-	return d.db.ExecuteArray(sql, []interface{}{string(engagement.JSONResponse), engagement.ChannelID, string(engagement.EngagementType)})
-}
-
-// GetEngagementHistory retrieves the engagement history for a channel and type
-func (d *Database) GetEngagementHistory(channelID string, engagementType EngagementType, limit int) ([]*ChannelEngagement, error) {
-	sql := `SELECT id, channel_id, engagement_type, create_date, update_date, json_response 
-			FROM channel_engagement 
-			WHERE channel_id = ? AND engagement_type = ?
-			ORDER BY create_date DESC LIMIT ?`
-
-	result, err := d.db.SelectArray(sql, []interface{}{channelID, string(engagementType), limit})
-	if err != nil {
-		return nil, err
-	}
-
-	var engagements []*ChannelEngagement
-	rowCount := result.GetNumberOfRows()
-
-	for r := uint64(0); r < rowCount; r++ {
-		id, _ := result.GetInt64Value(r, 0)
-		channelIDValue, _ := result.GetStringValue(r, 1)
-		engagementTypeValue, _ := result.GetStringValue(r, 2)
-		createDateStr, _ := result.GetStringValue(r, 3)
-		updateDateStr, _ := result.GetStringValue(r, 4)
-		jsonResponse, _ := result.GetStringValue(r, 5)
-
-		createDate, _ := time.Parse("2006-01-02 15:04:05", createDateStr)
-		updateDate, _ := time.Parse("2006-01-02 15:04:05", updateDateStr)
-
-		engagement := &ChannelEngagement{
-			ID:             id,
-			ChannelID:      channelIDValue,
-			EngagementType: EngagementType(engagementTypeValue),
-			CreateDate:     createDate,
-			UpdateDate:     updateDate,
-			JSONResponse:   json.RawMessage(jsonResponse),
-		}
-		engagements = append(engagements, engagement)
-	}
-
-	return engagements, nil
-}
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	sqlitecloud "github.com/sqlitecloud/sqlitecloud-go"
+)
+
+// EngagementType represents the type of engagement data
+type EngagementType string
+
+const (
+	EngagementTypeAnalytics EngagementType = "analytics"
+	EngagementTypeTrends    EngagementType = "trends"
+)
+
+// ChannelEngagement represents a record in the channel_engagement table
+type ChannelEngagement struct {
+	ID             int64           `json:"id"`
+	ChannelID      string          `json:"channel_id"`
+	EngagementType EngagementType  `json:"engagement_type"`
+	CreateDate     time.Time       `json:"create_date"`
+	UpdateDate     time.Time       `json:"update_date"`
+	JSONResponse   json.RawMessage `json:"json_response"`
+	VersionDate    string          `json:"version_date,omitempty"`
+}
+
+// EngagementVersion is a single dated version of an analytics or trends result
+type EngagementVersion struct {
+	VersionDate string          `json:"versionDate"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Data        json.RawMessage `json:"data"`
+}
+
+// EngagementHistory is a page of dated versions for a channel, newest first
+type EngagementHistory struct {
+	ChannelID      string              `json:"channelId"`
+	EngagementType EngagementType      `json:"engagementType"`
+	Total          int64               `json:"total"`
+	Limit          int                 `json:"limit"`
+	Offset         int                 `json:"offset"`
+	Versions       []EngagementVersion `json:"versions"`
+}
+
+// CreateChannelEngagementTable creates the channel_engagement table if it doesn't exist
+func (d *Database) CreateChannelEngagementTable() error {
+	sql := `
+	CREATE TABLE IF NOT EXISTS channel_engagement (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		channel_id TEXT NOT NULL,
+		engagement_type TEXT NOT NULL CHECK(engagement_type IN ('analytics', 'trends')),
+		create_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		update_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		json_response JSON NOT NULL,
+		UNIQUE(channel_id, engagement_type)
+	);
+	CREATE INDEX IF NOT EXISTS idx_channel_engagement_channel_id ON channel_engagement(channel_id);
+	CREATE INDEX IF NOT EXISTS idx_channel_engagement_type ON channel_engagement(engagement_type);
+	`
+	return d.executeSQL(sql)
+}
+
+// StoreEngagement stores a new engagement record and today's dated version of it
+// in one transaction, so the latest record and the history never disagree
+func (d *Database) StoreEngagement(engagement *ChannelEngagement) error {
+	log.Printf("Storing engagement for channel %s, type %s", engagement.ChannelID, engagement.EngagementType)
+
+	// Insert or update in a single statement so concurrent writers cannot race
+	// between checking for the record and writing it
+	sql := `INSERT INTO channel_engagement
+		   (channel_id, engagement_type, json_response)
+		   VALUES (?, ?, ?)
+		   ON CONFLICT(channel_id, engagement_type) DO UPDATE SET
+			   json_response = excluded.json_response,
+			   update_date = CURRENT_TIMESTAMP`
+	args := []interface{}{engagement.ChannelID, string(engagement.EngagementType), string(engagement.JSONResponse)}
+
+	// Keep one dated version per day so the history can be replayed later
+	historySQL := `INSERT INTO channel_engagement_history
+				   (channel_id, engagement_type, version_date, json_response)
+				   VALUES (?, ?, date('now'), ?)
+				   ON CONFLICT(channel_id, engagement_type, version_date) DO UPDATE SET
+					   json_response = excluded.json_response,
+					   update_date = CURRENT_TIMESTAMP`
+	historyPayload, err := d.encodePayload(engagement.JSONResponse)
+	if err != nil {
+		return err
+	}
+	historyArgs := []interface{}{engagement.ChannelID, string(engagement.EngagementType), historyPayload}
+
+	err = d.transaction(func(exec func(string, []interface{}) error, query func(string, []interface{}) (*sqlitecloud.Result, error)) error {
+		if err := exec(sql, args); err != nil {
+			return fmt.Errorf("failed to store engagement: %v", err)
+		}
+		if err := exec(historySQL, historyArgs); err != nil {
+			return fmt.Errorf("failed to store engagement history: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error storing engagement: %v", err)
+		return err
+	}
+
+	log.Printf("Successfully stored engagement")
+	return nil
+}
+
+// GetLatestEngagement retrieves the latest engagement record for a channel and type
+func (d *Database) GetLatestEngagement(channelID string, engagementType EngagementType) (*ChannelEngagement, error) {
+	sql := `SELECT id, channel_id, engagement_type, create_date, update_date, json_response 
+			FROM channel_engagement 
+			WHERE channel_id = ? AND engagement_type = ?
+			ORDER BY create_date DESC LIMIT 1`
+
+	result, err := d.selectArray(sql, []interface{}{channelID, string(engagementType)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest engagement: %v", err)
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil // No record found
+	}
+
+	// Parse the fields
+	id, err := result.GetInt64Value(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse id: %v", err)
+	}
+
+	channelIDValue, err := result.GetStringValue(0, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse channel_id: %v", err)
+	}
+
+	engagementTypeValue, err := result.GetStringValue(0, 2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse engagement_type: %v", err)
+	}
+
+	createDateStr, err := result.GetStringValue(0, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse create_date: %v", err)
+	}
+
+	updateDateStr, err := result.GetStringValue(0, 4)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse update_date: %v", err)
+	}
+
+	jsonResponse, err := result.GetStringValue(0, 5)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse json_response: %v", err)
+	}
+
+	createDate, err := time.Parse("2006-01-02 15:04:05", createDateStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse create_date: %v", err)
+	}
+
+	updateDate, err := time.Parse("2006-01-02 15:04:05", updateDateStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse update_date: %v", err)
+	}
+
+	engagement := &ChannelEngagement{
+		ID:             id,
+		ChannelID:      channelIDValue,
+		EngagementType: EngagementType(engagementTypeValue),
+		CreateDate:     createDate,
+		UpdateDate:     updateDate,
+		JSONResponse:   json.RawMessage(jsonResponse),
+	}
+
+	return engagement, nil
+}
+
+// UpdateEngagement updates an existing engagement record
+func (d *Database) UpdateEngagement(engagement *ChannelEngagement) error {
+	sql := `UPDATE channel_engagement 
+			SET json_response = ?, update_date = CURRENT_TIMESTAMP 
+			WHERE channel_id = ? AND engagement_type = ?`
+
+	return d.executeArray(sql, []interface{}{string(engagement.JSONResponse), engagement.ChannelID, string(engagement.EngagementType)})
+}
+
+// GetEngagementHistory retrieves dated versions for a channel and type, newest first
+func (d *Database) GetEngagementHistory(channelID string, engagementType EngagementType, limit, offset int) ([]*ChannelEngagement, error) {
+	sql := `SELECT id, channel_id, engagement_type, create_date, update_date, json_response, version_date
+			FROM channel_engagement_history
+			WHERE channel_id = ? AND engagement_type = ?
+			ORDER BY version_date DESC LIMIT ? OFFSET ?`
+
+	result, err := d.selectArray(sql, []interface{}{channelID, string(engagementType), limit, offset})
+	if err != nil {
+		return nil, err
+	}
+
+	var engagements []*ChannelEngagement
+	rowCount := result.GetNumberOfRows()
+
+	for r := uint64(0); r < rowCount; r++ {
+		id, _ := result.GetInt64Value(r, 0)
+		channelIDValue, _ := result.GetStringValue(r, 1)
+		engagementTypeValue, _ := result.GetStringValue(r, 2)
+		createDateStr, _ := result.GetStringValue(r, 3)
+		updateDateStr, _ := result.GetStringValue(r, 4)
+		storedResponse, _ := result.GetStringValue(r, 5)
+		versionDate, _ := result.GetStringValue(r, 6)
+
+		jsonResponse, err := decodePayload(storedResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		createDate, _ := time.Parse("2006-01-02 15:04:05", createDateStr)
+		updateDate, _ := time.Parse("2006-01-02 15:04:05", updateDateStr)
+
+		engagement := &ChannelEngagement{
+			ID:             id,
+			ChannelID:      channelIDValue,
+			EngagementType: EngagementType(engagementTypeValue),
+			CreateDate:     createDate,
+			UpdateDate:     updateDate,
+			JSONResponse:   json.RawMessage(jsonResponse),
+			VersionDate:    versionDate,
+		}
+		engagements = append(engagements, engagement)
+	}
+
+	return engagements, nil
+}
+
+// CountEngagementHistory returns how many dated versions exist for a channel and type
+func (d *Database) CountEngagementHistory(channelID string, engagementType EngagementType) (int64, error) {
+	sql := `SELECT COUNT(*) FROM channel_engagement_history
+			WHERE channel_id = ? AND engagement_type = ?`
+
+	result, err := d.selectArray(sql, []interface{}{channelID, string(engagementType)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count engagement history: %v", err)
+	}
+
+	return result.GetInt64Value(0, 0)
+}
+
+// GetEngagementAsOf retrieves the newest version recorded on or before the given date.
+// It returns nil if the channel has no version that old.
+func (d *Database) GetEngagementAsOf(channelID string, engagementType EngagementType, asOf time.Time) (*ChannelEngagement, error) {
+	sql := `SELECT id, channel_id, engagement_type, create_date, update_date, json_response, version_date
+			FROM channel_engagement_history
+			WHERE channel_id = ? AND engagement_type = ? AND version_date <= ?
+			ORDER BY version_date DESC LIMIT 1`
+
+	result, err := d.selectArray(sql, []interface{}{channelID, string(engagementType), asOf.UTC().Format("2006-01-02")})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get engagement as of %s: %v", asOf.Format("2006-01-02"), err)
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+
+	createDate, err := time.Parse("2006-01-02 15:04:05", result.GetStringValue_(0, 3))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse create_date: %v", err)
+	}
+
+	updateDate, err := time.Parse("2006-01-02 15:04:05", result.GetStringValue_(0, 4))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse update_date: %v", err)
+	}
+
+	jsonResponse, err := decodePayload(result.GetStringValue_(0, 5))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ChannelEngagement{
+		ID:             result.GetInt64Value_(0, 0),
+		ChannelID:      result.GetStringValue_(0, 1),
+		EngagementType: EngagementType(result.GetStringValue_(0, 2)),
+		CreateDate:     createDate,
+		UpdateDate:     updateDate,
+		JSONResponse:   json.RawMessage(jsonResponse),
+		VersionDate:    result.GetStringValue_(0, 6),
+	}, nil
+}
+
+// Version converts a history record into its API representation
+func (e *ChannelEngagement) Version() EngagementVersion {
+	return EngagementVersion{
+		VersionDate: e.VersionDate,
+		CreatedAt:   e.CreateDate,
+		UpdatedAt:   e.UpdateDate,
+		Data:        e.JSONResponse,
+	}
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// UpsertChannel inserts a channel row or refreshes its statistics if it already exists
+func (d *Database) UpsertChannel(channel *Channel) error {
+	sql := `INSERT INTO channels
+			(id, title, description, thumbnail_url, uploads_playlist_id,
+			 subscriber_count, view_count, video_count, fetched_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(id) DO UPDATE SET
+				title = excluded.title,
+				description = excluded.description,
+				thumbnail_url = excluded.thumbnail_url,
+				uploads_playlist_id = excluded.uploads_playlist_id,
+				subscriber_count = excluded.subscriber_count,
+				view_count = excluded.view_count,
+				video_count = excluded.video_count,
+				fetched_at = CURRENT_TIMESTAMP`
+
+	args := []interface{}{
+		channel.ID,
+		channel.Title,
+		channel.Description,
+		channel.Thumbnail,
+		channel.UploadsPlaylistID,
+		channel.Subscribers,
+		channel.ViewCount,
+		channel.VideoCount,
+	}
+
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to upsert channel %s: %v", channel.ID, err)
+	}
+	return nil
+}
+
+// GetChannel retrieves a stored channel by ID, returning nil if it has never been fetched
+func (d *Database) GetChannel(channelID string) (*Channel, error) {
+	sql := `SELECT id, title, description, thumbnail_url, uploads_playlist_id,
+			subscriber_count, view_count, video_count, fetched_at,
+			COALESCE(videos_synced_at, '')
+			FROM channels WHERE id = ?`
+
+	result, err := d.selectArray(sql, []interface{}{channelID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel: %v", err)
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+
+	fetchedAt, err := time.Parse(timestampLayout, result.GetStringValue_(0, 8))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fetched_at: %v", err)
+	}
+
+	var videosSyncedAt time.Time
+	if stored := result.GetStringValue_(0, 9); stored != "" {
+		if videosSyncedAt, err = time.Parse(timestampLayout, stored); err != nil {
+			return nil, fmt.Errorf("failed to parse videos_synced_at: %v", err)
+		}
+	}
+
+	return &Channel{
+		ID:                result.GetStringValue_(0, 0),
+		Title:             result.GetStringValue_(0, 1),
+		Description:       result.GetStringValue_(0, 2),
+		Thumbnail:         result.GetStringValue_(0, 3),
+		UploadsPlaylistID: result.GetStringValue_(0, 4),
+		Subscribers:       result.GetInt64Value_(0, 5),
+		ViewCount:         result.GetInt64Value_(0, 6),
+		VideoCount:        result.GetInt64Value_(0, 7),
+		FetchedAt:         fetchedAt,
+		VideosSyncedAt:    videosSyncedAt,
+	}, nil
+}
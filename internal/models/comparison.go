@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Comparison metric names, in the order they are reported
+const (
+	MetricSubscribers               = "subscriberCount"
+	MetricViews                     = "viewCount"
+	MetricVideos                    = "videoCount"
+	MetricAverageViews              = "averageViews"
+	MetricLikeToViewRatio           = "likeToViewRatio"
+	MetricCommentToViewRatio        = "commentToViewRatio"
+	MetricViewsPerSubscriber        = "viewsPerSubscriber"
+	MetricAverageViewsPerSubscriber = "averageViewsPerSubscriber"
+	MetricViewsPerVideo             = "viewsPerVideo"
+	MetricLikesPerVideo             = "likesPerVideo"
+	MetricCommentsPerVideo          = "commentsPerVideo"
+)
+
+// ComparisonMetric is one channel's value of a metric and how it stands against the others
+type ComparisonMetric struct {
+	Value float64 `json:"value"`
+	// Rank is 1 for the highest value; equal values share a rank
+	Rank int `json:"rank"`
+	// PercentDiff is the difference from the base channel, omitted when its value is 0
+	PercentDiff *float64 `json:"percentDiff,omitempty"`
+}
+
+// MonthlyComparison is a channel's uploads and average engagement in one month
+type MonthlyComparison struct {
+	Period          string    `json:"period"`
+	PeriodStart     time.Time `json:"periodStart"`
+	PeriodEnd       time.Time `json:"periodEnd"`
+	Uploads         int       `json:"uploads"`
+	AverageViews    float64   `json:"averageViews"`
+	AverageLikes    float64   `json:"averageLikes"`
+	AverageComments float64   `json:"averageComments"`
+}
+
+// ComparedChannel is one channel's side of a comparison
+type ComparedChannel struct {
+	ChannelID    string                      `json:"channelId"`
+	ChannelTitle string                      `json:"channelTitle"`
+	Thumbnail    string                      `json:"thumbnailUrl"`
+	TotalVideos  int                         `json:"totalVideos"`
+	Metrics      map[string]ComparisonMetric `json:"metrics"`
+	// Monthly lines up with ChannelComparison.Months
+	Monthly []MonthlyComparison `json:"monthly"`
+}
+
+// ChannelComparison puts several channels' metrics side by side
+type ChannelComparison struct {
+	// BaseChannelID is the first channel requested, which percent differences are measured from
+	BaseChannelID string   `json:"baseChannelId"`
+	Metrics       []string `json:"metrics"`
+	// Months runs without gaps from the earliest upload of any channel to the latest
+	Months    []string          `json:"months"`
+	Channels  []ComparedChannel `json:"channels"`
+	Timestamp time.Time         `json:"timestamp"`
+}
@@ -1,181 +1,429 @@
-package models
-
-import (
-	"encoding/json"
-	"fmt"
-	"log"
-	"strings"
-
-	sqlitecloud "github.com/sqlitecloud/sqlitecloud-go"
-)
-
-// Database represents the database connection and operations
-type Database struct {
-	db *sqlitecloud.SQCloud
-}
-
-// NewDatabase creates a new database connection
-func NewDatabase(dbPath string) (*Database, error) {
-	log.Printf("Connecting to SQLite Cloud database: %s", maskConnectionString(dbPath))
-
-	// Connect to SQLite Cloud
-	db, err := sqlitecloud.Connect(dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to SQLite Cloud: %v", err)
-	}
-
-	database := &Database{
-		db: db,
-	}
-
-	// Create tables if they don't exist
-	if err := database.createTables(); err != nil {
-		return nil, err
-	}
-
-	return database, nil
-}
-
-// maskConnectionString hides the API key in logs for security
-func maskConnectionString(connStr string) string {
-	if strings.Contains(connStr, "apikey=") {
-		parts := strings.Split(connStr, "apikey=")
-		if len(parts) > 1 {
-			return parts[0] + "apikey=***"
-		}
-	}
-	return connStr
-}
-
-// executeSQL executes a SQL command using SQLite Cloud
-func (d *Database) executeSQL(sql string, args ...interface{}) error {
-	// Use SQLite Cloud's Execute method for DDL/DML operations
-	if len(args) > 0 {
-		return d.db.ExecuteArray(sql, args)
-	}
-	return d.db.Execute(sql)
-}
-
-// createTables creates the necessary tables if they don't exist
-func (d *Database) createTables() error {
-	tables := []string{
-		`CREATE TABLE IF NOT EXISTS channel_engagement (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			channel_id TEXT NOT NULL,
-			engagement_type TEXT NOT NULL CHECK(engagement_type IN ('analytics', 'trends')),
-			create_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			update_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
-			json_response TEXT NOT NULL,
-			CONSTRAINT unique_channel_engagement UNIQUE(channel_id, engagement_type)
-		)`,
-		`CREATE TABLE IF NOT EXISTS channel_analytics (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			channel_id TEXT NOT NULL,
-			channel_name TEXT NOT NULL,
-			analytics_data TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-		`CREATE TABLE IF NOT EXISTS channel_trends (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			channel_id TEXT NOT NULL,
-			channel_name TEXT NOT NULL,
-			trends_data TEXT NOT NULL,
-			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-		)`,
-	}
-
-	for _, table := range tables {
-		if err := d.executeSQL(table); err != nil {
-			return fmt.Errorf("failed to create table: %v", err)
-		}
-	}
-	return nil
-}
-
-// StoreAnalytics stores channel analytics data
-func (d *Database) StoreAnalytics(channelID, channelName string, analytics *ChannelAnalytics) error {
-	data, err := json.Marshal(analytics)
-	if err != nil {
-		return err
-	}
-
-	sql := `INSERT INTO channel_analytics (channel_id, channel_name, analytics_data)
-			VALUES (?, ?, ?)`
-
-	return d.db.ExecuteArray(sql, []interface{}{channelID, channelName, string(data)})
-}
-
-// StoreTrends stores channel trends data
-func (d *Database) StoreTrends(channelID, channelName string, trends *ChannelTrends) error {
-	data, err := json.Marshal(trends)
-	if err != nil {
-		return err
-	}
-
-	sql := `INSERT INTO channel_trends (channel_id, channel_name, trends_data)
-			VALUES (?, ?, ?)`
-
-	return d.db.ExecuteArray(sql, []interface{}{channelID, channelName, string(data)})
-}
-
-// GetLatestAnalytics retrieves the latest analytics for a channel
-func (d *Database) GetLatestAnalytics(channelID string) (*ChannelAnalytics, error) {
-	sql := `SELECT analytics_data FROM channel_analytics 
-			WHERE channel_id = ? 
-			ORDER BY created_at DESC LIMIT 1`
-
-	result, err := d.db.SelectArray(sql, []interface{}{channelID})
-	if err != nil {
-		return nil, err
-	}
-
-	if result.GetNumberOfRows() == 0 {
-		return nil, fmt.Errorf("no analytics found for channel %s", channelID)
-	}
-
-	analyticsData, err := result.GetStringValue(0, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var analytics ChannelAnalytics
-	if err := json.Unmarshal([]byte(analyticsData), &analytics); err != nil {
-		return nil, err
-	}
-	return &analytics, nil
-}
-
-// GetLatestTrends retrieves the latest trends for a channel
-func (d *Database) GetLatestTrends(channelID string) (*ChannelTrends, error) {
-	sql := `SELECT trends_data FROM channel_trends 
-			WHERE channel_id = ? 
-			ORDER BY created_at DESC LIMIT 1`
-
-	result, err := d.db.SelectArray(sql, []interface{}{channelID})
-	if err != nil {
-		return nil, err
-	}
-
-	if result.GetNumberOfRows() == 0 {
-		return nil, fmt.Errorf("no trends found for channel %s", channelID)
-	}
-
-	trendsData, err := result.GetStringValue(0, 0)
-	if err != nil {
-		return nil, err
-	}
-
-	var trends ChannelTrends
-	if err := json.Unmarshal([]byte(trendsData), &trends); err != nil {
-		return nil, err
-	}
-	return &trends, nil
-}
-
-// Close closes the database connection
-func (d *Database) Close() error {
-	if d.db != nil {
-		return d.db.Close()
-	}
-	return nil
-}
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	sqlitecloud "github.com/sqlitecloud/sqlitecloud-go"
+)
+
+// timestampLayout matches the format SQLite uses for CURRENT_TIMESTAMP
+const timestampLayout = "2006-01-02 15:04:05"
+
+// Database represents the database connection and operations
+type Database struct {
+	db *sqlitecloud.SQCloud
+	// mu serializes access to the single SQLite Cloud connection, which is not
+	// safe for concurrent use by handlers and background workers
+	mu sync.Mutex
+	// compressPayloads gzips JSON payloads written to the history tables
+	compressPayloads bool
+}
+
+// NewDatabase creates a new database connection
+func NewDatabase(dbPath string) (*Database, error) {
+	log.Printf("Connecting to SQLite Cloud database: %s", maskConnectionString(dbPath))
+
+	// Connect to SQLite Cloud
+	db, err := sqlitecloud.Connect(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SQLite Cloud: %v", err)
+	}
+
+	database := &Database{
+		db: db,
+	}
+
+	// Create tables if they don't exist
+	if err := database.createTables(); err != nil {
+		return nil, err
+	}
+
+	return database, nil
+}
+
+// maskConnectionString hides the API key in logs for security
+func maskConnectionString(connStr string) string {
+	if strings.Contains(connStr, "apikey=") {
+		parts := strings.Split(connStr, "apikey=")
+		if len(parts) > 1 {
+			return parts[0] + "apikey=***"
+		}
+	}
+	return connStr
+}
+
+// executeSQL executes a SQL command using SQLite Cloud
+func (d *Database) executeSQL(sql string, args ...interface{}) error {
+	// Use SQLite Cloud's Execute method for DDL/DML operations
+	if len(args) > 0 {
+		return d.executeArray(sql, args)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.Execute(sql)
+}
+
+// executeArray runs a parameterized statement on the shared connection
+func (d *Database) executeArray(sql string, args []interface{}) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.ExecuteArray(sql, args)
+}
+
+// selectArray runs a parameterized query on the shared connection
+func (d *Database) selectArray(sql string, args []interface{}) (*sqlitecloud.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.SelectArray(sql, args)
+}
+
+// transaction runs statements as a single transaction on the shared connection,
+// rolling back if they fail. statements must use the exec and query it is given.
+func (d *Database) transaction(statements func(exec func(string, []interface{}) error, query func(string, []interface{}) (*sqlitecloud.Result, error)) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.db.Execute("BEGIN TRANSACTION"); err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	if err := statements(d.db.ExecuteArray, d.db.SelectArray); err != nil {
+		if rollbackErr := d.db.Execute("ROLLBACK"); rollbackErr != nil {
+			log.Printf("Failed to roll back transaction: %v", rollbackErr)
+		}
+		return err
+	}
+	if err := d.db.Execute("COMMIT"); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// SetPayloadCompression enables or disables gzip compression of JSON payloads
+// written to channel_analytics, channel_trends and channel_engagement_history
+func (d *Database) SetPayloadCompression(enabled bool) {
+	d.compressPayloads = enabled
+}
+
+// createTables creates the necessary tables if they don't exist
+func (d *Database) createTables() error {
+	tables := []string{
+		`CREATE TABLE IF NOT EXISTS channel_engagement (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel_id TEXT NOT NULL,
+			engagement_type TEXT NOT NULL CHECK(engagement_type IN ('analytics', 'trends')),
+			create_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			update_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			json_response TEXT NOT NULL,
+			CONSTRAINT unique_channel_engagement UNIQUE(channel_id, engagement_type)
+		)`,
+		`CREATE TABLE IF NOT EXISTS channel_analytics (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel_id TEXT NOT NULL,
+			channel_name TEXT NOT NULL,
+			analytics_data TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS channel_trends (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel_id TEXT NOT NULL,
+			channel_name TEXT NOT NULL,
+			trends_data TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS channels (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			thumbnail_url TEXT NOT NULL DEFAULT '',
+			uploads_playlist_id TEXT NOT NULL DEFAULT '',
+			subscriber_count INTEGER NOT NULL DEFAULT 0,
+			view_count INTEGER NOT NULL DEFAULT 0,
+			video_count INTEGER NOT NULL DEFAULT 0,
+			fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			videos_synced_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_channels_subscriber_count ON channels(subscriber_count)`,
+		`CREATE TABLE IF NOT EXISTS videos (
+			id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			title TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			published_at TIMESTAMP NOT NULL,
+			duration TEXT NOT NULL DEFAULT '',
+			thumbnail_url TEXT NOT NULL DEFAULT '',
+			tags TEXT NOT NULL DEFAULT '[]',
+			view_count INTEGER NOT NULL DEFAULT 0,
+			like_count INTEGER NOT NULL DEFAULT 0,
+			comment_count INTEGER NOT NULL DEFAULT 0,
+			fetched_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_channel_published ON videos(channel_id, published_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_videos_view_count ON videos(view_count)`,
+		`CREATE TABLE IF NOT EXISTS channel_engagement_history (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel_id TEXT NOT NULL,
+			engagement_type TEXT NOT NULL CHECK(engagement_type IN ('analytics', 'trends')),
+			version_date DATE NOT NULL,
+			create_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			update_date TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			json_response TEXT NOT NULL,
+			CONSTRAINT unique_engagement_version UNIQUE(channel_id, engagement_type, version_date)
+		)`,
+		// Seed the history with whatever the upsert-only table already holds
+		`INSERT OR IGNORE INTO channel_engagement_history
+			(channel_id, engagement_type, version_date, create_date, update_date, json_response)
+			SELECT channel_id, engagement_type, date(update_date), update_date, update_date, json_response
+			FROM channel_engagement`,
+		`CREATE TABLE IF NOT EXISTS cache_entries (
+			cache_key TEXT PRIMARY KEY,
+			resource TEXT NOT NULL,
+			value TEXT NOT NULL,
+			stored_at TIMESTAMP NOT NULL,
+			expires_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_cache_entries_expires_at ON cache_entries(expires_at)`,
+		`CREATE TABLE IF NOT EXISTS watchlist (
+			channel_id TEXT PRIMARY KEY,
+			channel_title TEXT NOT NULL DEFAULT '',
+			source_url TEXT NOT NULL DEFAULT '',
+			labels TEXT NOT NULL DEFAULT '[]',
+			notes TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS channel_snapshots (
+			channel_id TEXT NOT NULL,
+			snapshot_date DATE NOT NULL,
+			subscriber_count INTEGER NOT NULL DEFAULT 0,
+			view_count INTEGER NOT NULL DEFAULT 0,
+			video_count INTEGER NOT NULL DEFAULT 0,
+			captured_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (channel_id, snapshot_date)
+		)`,
+		`CREATE TABLE IF NOT EXISTS video_snapshots (
+			video_id TEXT NOT NULL,
+			channel_id TEXT NOT NULL,
+			snapshot_date DATE NOT NULL,
+			view_count INTEGER NOT NULL DEFAULT 0,
+			like_count INTEGER NOT NULL DEFAULT 0,
+			comment_count INTEGER NOT NULL DEFAULT 0,
+			captured_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (video_id, snapshot_date)
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_video_snapshots_channel ON video_snapshots(channel_id, snapshot_date)`,
+		`CREATE TABLE IF NOT EXISTS job_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			job_name TEXT NOT NULL,
+			channel_id TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL,
+			quota_units INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			finished_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_job_runs_started_at ON job_runs(started_at)`,
+		`CREATE TABLE IF NOT EXISTS analytics_jobs (
+			id TEXT PRIMARY KEY,
+			channel_id TEXT NOT NULL,
+			status TEXT NOT NULL,
+			videos_fetched INTEGER NOT NULL DEFAULT 0,
+			videos_total INTEGER NOT NULL DEFAULT 0,
+			result TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			finished_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_analytics_jobs_status ON analytics_jobs(status, channel_id)`,
+		`CREATE TABLE IF NOT EXISTS websub_subscriptions (
+			channel_id TEXT PRIMARY KEY,
+			topic TEXT NOT NULL UNIQUE,
+			status TEXT NOT NULL,
+			lease_seconds INTEGER NOT NULL DEFAULT 0,
+			expires_at TIMESTAMP,
+			last_notification_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE IF NOT EXISTS alert_rules (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			channel_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			threshold REAL NOT NULL DEFAULT 0,
+			window_hours INTEGER NOT NULL DEFAULT 0,
+			webhook_url TEXT NOT NULL,
+			secret TEXT NOT NULL DEFAULT '',
+			enabled INTEGER NOT NULL DEFAULT 1,
+			state TEXT NOT NULL DEFAULT '',
+			last_triggered_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_alert_rules_channel ON alert_rules(channel_id)`,
+		`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			rule_id INTEGER NOT NULL,
+			event_type TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			response_status INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			delivered_at TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_rule ON webhook_deliveries(rule_id, id)`,
+		`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries(status, next_attempt_at)`,
+	}
+
+	for _, table := range tables {
+		if err := d.executeSQL(table); err != nil {
+			return fmt.Errorf("failed to create table: %v", err)
+		}
+	}
+	return d.addMissingColumns()
+}
+
+// addedColumns lists columns added to tables after they were first created
+var addedColumns = []struct {
+	table, column, definition string
+}{
+	{"videos", "tags", "TEXT NOT NULL DEFAULT '[]'"},
+	{"channels", "videos_synced_at", "TIMESTAMP"},
+}
+
+// addMissingColumns brings tables created by earlier versions up to date
+func (d *Database) addMissingColumns() error {
+	for _, c := range addedColumns {
+		result, err := d.selectArray(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, []interface{}{c.table, c.column})
+		if err != nil {
+			return fmt.Errorf("failed to inspect table %s: %v", c.table, err)
+		}
+		if result.GetNumberOfRows() > 0 && result.GetInt64Value_(0, 0) > 0 {
+			continue
+		}
+
+		sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.column, c.definition)
+		if err := d.executeSQL(sql); err != nil {
+			return fmt.Errorf("failed to add column %s.%s: %v", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// StoreAnalytics stores channel analytics data
+func (d *Database) StoreAnalytics(channelID, channelName string, analytics *ChannelAnalytics) error {
+	data, err := json.Marshal(analytics)
+	if err != nil {
+		return err
+	}
+
+	payload, err := d.encodePayload(data)
+	if err != nil {
+		return err
+	}
+
+	sql := `INSERT INTO channel_analytics (channel_id, channel_name, analytics_data)
+			VALUES (?, ?, ?)`
+
+	return d.executeArray(sql, []interface{}{channelID, channelName, payload})
+}
+
+// StoreTrends stores channel trends data
+func (d *Database) StoreTrends(channelID, channelName string, trends *ChannelTrends) error {
+	data, err := json.Marshal(trends)
+	if err != nil {
+		return err
+	}
+
+	payload, err := d.encodePayload(data)
+	if err != nil {
+		return err
+	}
+
+	sql := `INSERT INTO channel_trends (channel_id, channel_name, trends_data)
+			VALUES (?, ?, ?)`
+
+	return d.executeArray(sql, []interface{}{channelID, channelName, payload})
+}
+
+// GetLatestAnalytics retrieves the latest analytics for a channel
+func (d *Database) GetLatestAnalytics(channelID string) (*ChannelAnalytics, error) {
+	sql := `SELECT analytics_data FROM channel_analytics 
+			WHERE channel_id = ? 
+			ORDER BY created_at DESC LIMIT 1`
+
+	result, err := d.selectArray(sql, []interface{}{channelID})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, fmt.Errorf("no analytics found for channel %s", channelID)
+	}
+
+	analyticsData, err := result.GetStringValue(0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodePayload(analyticsData)
+	if err != nil {
+		return nil, err
+	}
+
+	var analytics ChannelAnalytics
+	if err := json.Unmarshal(data, &analytics); err != nil {
+		return nil, err
+	}
+	return &analytics, nil
+}
+
+// GetLatestTrends retrieves the latest trends for a channel
+func (d *Database) GetLatestTrends(channelID string) (*ChannelTrends, error) {
+	sql := `SELECT trends_data FROM channel_trends 
+			WHERE channel_id = ? 
+			ORDER BY created_at DESC LIMIT 1`
+
+	result, err := d.selectArray(sql, []interface{}{channelID})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, fmt.Errorf("no trends found for channel %s", channelID)
+	}
+
+	trendsData, err := result.GetStringValue(0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := decodePayload(trendsData)
+	if err != nil {
+		return nil, err
+	}
+
+	var trends ChannelTrends
+	if err := json.Unmarshal(data, &trends); err != nil {
+		return nil, err
+	}
+	return &trends, nil
+}
+
+// Close closes the database connection
+func (d *Database) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.db != nil {
+		return d.db.Close()
+	}
+	return nil
+}
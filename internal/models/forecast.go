@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Forecast model names
+const (
+	ForecastLinear    = "linear"
+	ForecastLogLinear = "loglinear"
+	ForecastHolt      = "holt"
+)
+
+// ForecastPoint is a projected value with its prediction interval
+type ForecastPoint struct {
+	HorizonDays int       `json:"horizonDays"`
+	Date        time.Time `json:"date"`
+	Value       float64   `json:"value"`
+	Lower       float64   `json:"lower"`
+	Upper       float64   `json:"upper"`
+}
+
+// ModelForecast is one model's projection of a metric
+type ModelForecast struct {
+	Model string `json:"model"`
+	// RMSE is the model's root mean squared error predicting each snapshot from the ones before it
+	RMSE   float64         `json:"rmse"`
+	Points []ForecastPoint `json:"points"`
+}
+
+// MetricForecast holds every model's projection of one channel statistic
+type MetricForecast struct {
+	Metric  string `json:"metric"`
+	Current int64  `json:"current"`
+	// BestModel is the model with the lowest RMSE
+	BestModel string          `json:"bestModel"`
+	Models    []ModelForecast `json:"models"`
+}
+
+// MilestoneETA estimates when a channel reaches its next subscriber milestone
+type MilestoneETA struct {
+	Milestone int64  `json:"milestone"`
+	Model     string `json:"model"`
+	// Date is omitted when the milestone is not reached within ten years
+	Date     *time.Time `json:"date,omitempty"`
+	DaysAway *int       `json:"daysAway,omitempty"`
+}
+
+// ChannelForecast projects a channel's subscribers and views from its daily snapshots
+type ChannelForecast struct {
+	ChannelID    string     `json:"channelId"`
+	ChannelTitle string     `json:"channelTitle"`
+	Snapshots    int        `json:"snapshots"`
+	From         *time.Time `json:"from,omitempty"`
+	To           *time.Time `json:"to,omitempty"`
+	// Confidence is the coverage of the prediction intervals
+	Confidence          float64         `json:"confidence"`
+	Subscribers         *MetricForecast `json:"subscribers,omitempty"`
+	Views               *MetricForecast `json:"views,omitempty"`
+	SubscriberMilestone *MilestoneETA   `json:"subscriberMilestone,omitempty"`
+	// Message explains why forecasts are missing
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	CacheMetadata
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobRunStatus is the state or outcome of a background job
+type JobRunStatus string
+
+const (
+	JobRunStatusQueued    JobRunStatus = "queued"
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusSucceeded JobRunStatus = "succeeded"
+	JobRunStatusFailed    JobRunStatus = "failed"
+	JobRunStatusSkipped   JobRunStatus = "skipped"
+)
+
+// JobRun is a row in the job_runs table recording one unit of scheduled work
+type JobRun struct {
+	ID         int64        `json:"id"`
+	JobName    string       `json:"jobName"`
+	ChannelID  string       `json:"channelId,omitempty"`
+	Status     JobRunStatus `json:"status"`
+	QuotaUnits int64        `json:"quotaUnits"`
+	Error      string       `json:"error,omitempty"`
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt *time.Time   `json:"finishedAt,omitempty"`
+}
+
+// StartJobRun records the start of a job run and returns its ID
+func (d *Database) StartJobRun(jobName, channelID string) (int64, error) {
+	sql := `INSERT INTO job_runs (job_name, channel_id, status) VALUES (?, ?, ?) RETURNING id`
+
+	result, err := d.selectArray(sql, []interface{}{jobName, channelID, string(JobRunStatusRunning)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start job run: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return 0, fmt.Errorf("failed to start job run: no id returned")
+	}
+	return result.GetInt64Value(0, 0)
+}
+
+// FinishJobRun records the outcome of a job run
+func (d *Database) FinishJobRun(id int64, status JobRunStatus, quotaUnits int64, runErr error) error {
+	errMsg := ""
+	if runErr != nil {
+		errMsg = runErr.Error()
+	}
+
+	sql := `UPDATE job_runs
+			SET status = ?, quota_units = ?, error = ?, finished_at = CURRENT_TIMESTAMP
+			WHERE id = ?`
+
+	if err := d.executeArray(sql, []interface{}{string(status), quotaUnits, errMsg, id}); err != nil {
+		return fmt.Errorf("failed to finish job run: %v", err)
+	}
+	return nil
+}
+
+// GetRecentJobRuns returns the most recent job runs, newest first
+func (d *Database) GetRecentJobRuns(limit int) ([]JobRun, error) {
+	sql := `SELECT id, job_name, channel_id, status, quota_units, error, started_at, finished_at
+			FROM job_runs ORDER BY id DESC LIMIT ?`
+
+	result, err := d.selectArray(sql, []interface{}{limit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job runs: %v", err)
+	}
+
+	runs := make([]JobRun, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		startedAt, err := time.Parse(timestampLayout, result.GetStringValue_(r, 6))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse started_at: %v", err)
+		}
+
+		run := JobRun{
+			ID:         result.GetInt64Value_(r, 0),
+			JobName:    result.GetStringValue_(r, 1),
+			ChannelID:  result.GetStringValue_(r, 2),
+			Status:     JobRunStatus(result.GetStringValue_(r, 3)),
+			QuotaUnits: result.GetInt64Value_(r, 4),
+			Error:      result.GetStringValue_(r, 5),
+			StartedAt:  startedAt,
+		}
+		if finished := result.GetStringValue_(r, 7); finished != "" {
+			if finishedAt, err := time.Parse(timestampLayout, finished); err == nil {
+				run.FinishedAt = &finishedAt
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
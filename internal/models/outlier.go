@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// OutlierDirection says which way a video departs from its channel's baseline
+type OutlierDirection string
+
+const (
+	OutlierOver  OutlierDirection = "over"
+	OutlierUnder OutlierDirection = "under"
+)
+
+// VideoBaseline expresses a video's views relative to the uploads around it
+type VideoBaseline struct {
+	Video Video `json:"video"`
+	// BaselineViews is the median views of the neighbouring uploads
+	BaselineViews float64 `json:"baselineViews"`
+	// Multiple is the video's views divided by BaselineViews
+	Multiple float64 `json:"multiple"`
+	// RobustZScore is the modified z-score of the video's log multiple across the channel
+	RobustZScore float64          `json:"robustZScore"`
+	Direction    OutlierDirection `json:"direction,omitempty"`
+}
+
+// ChannelOutliers lists a channel's over- and under-performing videos
+type ChannelOutliers struct {
+	ChannelID       string          `json:"channelId"`
+	ChannelTitle    string          `json:"channelTitle"`
+	TotalVideos     int             `json:"totalVideos"`
+	Window          int             `json:"window"`
+	Sensitivity     float64         `json:"sensitivity"`
+	Overperforming  []VideoBaseline `json:"overperforming"`
+	Underperforming []VideoBaseline `json:"underperforming"`
+	// Videos holds every video's baseline, oldest first, when requested with ?all=true
+	Videos    []VideoBaseline `json:"videos,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	CacheMetadata
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compressedPayloadPrefix marks a TEXT payload column holding base64 encoded gzip data
+const compressedPayloadPrefix = "gz:"
+
+// encodePayload prepares a JSON payload for storage, compressing it when enabled
+func (d *Database) encodePayload(data []byte) (string, error) {
+	if !d.compressPayloads {
+		return string(data), nil
+	}
+	return compressPayload(data)
+}
+
+// compressPayload gzips a JSON payload and encodes it so it fits in a TEXT column
+func compressPayload(data []byte) (string, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return "", fmt.Errorf("failed to compress payload: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress payload: %v", err)
+	}
+	return compressedPayloadPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decodePayload returns the raw JSON of a stored payload, whether or not it was compressed
+func decodePayload(stored string) ([]byte, error) {
+	if !strings.HasPrefix(stored, compressedPayloadPrefix) {
+		return []byte(stored), nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, compressedPayloadPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %v", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %v", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress payload: %v", err)
+	}
+	return data, nil
+}
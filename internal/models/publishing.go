@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// PublishingSlot summarizes the uploads published in one weekday and hour
+type PublishingSlot struct {
+	Weekday string `json:"weekday"`
+	Hour    int    `json:"hour"`
+	Uploads int    `json:"uploads"`
+	// MedianViews is the median views of the uploads in the slot
+	MedianViews float64 `json:"medianViews"`
+	// MedianMultiple is the median of the uploads' views relative to their baseline
+	MedianMultiple float64 `json:"medianMultiple"`
+}
+
+// PublishingWindow is a run of consecutive hours on one weekday to publish in
+type PublishingWindow struct {
+	Weekday   string `json:"weekday"`
+	StartHour int    `json:"startHour"`
+	// EndHour is exclusive and may be 24
+	EndHour        int     `json:"endHour"`
+	Uploads        int     `json:"uploads"`
+	MedianMultiple float64 `json:"medianMultiple"`
+}
+
+// PublishingHeatmap shows how a channel's uploads perform by weekday and hour
+type PublishingHeatmap struct {
+	ChannelID    string `json:"channelId"`
+	ChannelTitle string `json:"channelTitle"`
+	Timezone     string `json:"timezone"`
+	TotalVideos  int    `json:"totalVideos"`
+	// Slots holds every weekday and hour, Monday 00:00 first
+	Slots []PublishingSlot `json:"slots"`
+	// Recommended is omitted when no window has enough uploads to judge
+	Recommended *PublishingWindow `json:"recommended,omitempty"`
+	Timestamp   time.Time         `json:"timestamp"`
+	CacheMetadata
+}
@@ -0,0 +1,142 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PayloadTable describes a table that accumulates dated JSON payloads.
+// A table without a DataColumn holds dated rows that are thinned but never compressed.
+type PayloadTable struct {
+	Name         string
+	DataColumn   string
+	TimeColumn   string
+	GroupColumns []string
+}
+
+// PayloadTables lists every table the retention compactor manages
+var PayloadTables = []PayloadTable{
+	{Name: "channel_analytics", DataColumn: "analytics_data", TimeColumn: "created_at", GroupColumns: []string{"channel_id"}},
+	{Name: "channel_trends", DataColumn: "trends_data", TimeColumn: "created_at", GroupColumns: []string{"channel_id"}},
+	{Name: "channel_engagement_history", DataColumn: "json_response", TimeColumn: "create_date", GroupColumns: []string{"channel_id", "engagement_type"}},
+}
+
+// SnapshotTables lists the daily statistics tables the retention compactor thins out
+var SnapshotTables = []PayloadTable{
+	{Name: "channel_snapshots", TimeColumn: "snapshot_date", GroupColumns: []string{"channel_id"}},
+	{Name: "video_snapshots", TimeColumn: "snapshot_date", GroupColumns: []string{"video_id"}},
+}
+
+// PayloadRow is the metadata of a stored payload, without the payload itself
+type PayloadRow struct {
+	ID         int64
+	GroupKey   string
+	CreatedAt  time.Time
+	Size       int64
+	Compressed bool
+}
+
+// payloadDeleteBatchSize bounds the number of IDs in a single DELETE statement
+const payloadDeleteBatchSize = 200
+
+// ListPayloadRows returns the metadata of every row in a payload table.
+// Rows are identified by rowid, which is the id column where a table has one.
+func (d *Database) ListPayloadRows(table PayloadTable) ([]PayloadRow, error) {
+	payload := "0, 0"
+	if table.DataColumn != "" {
+		payload = fmt.Sprintf("length(%s), substr(%s, 1, %d) = '%s'",
+			table.DataColumn, table.DataColumn, len(compressedPayloadPrefix), compressedPayloadPrefix)
+	}
+	// datetime() also reads plain dates, such as a snapshot's
+	sql := fmt.Sprintf(`SELECT rowid, %s, datetime(%s), %s
+			FROM %s ORDER BY %s DESC`,
+		strings.Join(table.GroupColumns, " || '/' || "),
+		table.TimeColumn,
+		payload,
+		table.Name,
+		table.TimeColumn)
+
+	result, err := d.selectArray(sql, []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rows of %s: %v", table.Name, err)
+	}
+
+	rowCount := result.GetNumberOfRows()
+	rows := make([]PayloadRow, 0, rowCount)
+	for r := uint64(0); r < rowCount; r++ {
+		createdAt, err := time.Parse(timestampLayout, result.GetStringValue_(r, 2))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s.%s: %v", table.Name, table.TimeColumn, err)
+		}
+
+		rows = append(rows, PayloadRow{
+			ID:         result.GetInt64Value_(r, 0),
+			GroupKey:   result.GetStringValue_(r, 1),
+			CreatedAt:  createdAt,
+			Size:       result.GetInt64Value_(r, 3),
+			Compressed: result.GetInt64Value_(r, 4) == 1,
+		})
+	}
+
+	return rows, nil
+}
+
+// DeletePayloadRows removes the given rows from a payload table
+func (d *Database) DeletePayloadRows(table PayloadTable, ids []int64) error {
+	for i := 0; i < len(ids); i += payloadDeleteBatchSize {
+		end := i + payloadDeleteBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[i:end]
+
+		placeholders := make([]string, len(batch))
+		args := make([]interface{}, len(batch))
+		for j, id := range batch {
+			placeholders[j] = "?"
+			args[j] = id
+		}
+
+		sql := fmt.Sprintf("DELETE FROM %s WHERE rowid IN (%s)", table.Name, strings.Join(placeholders, ", "))
+		if err := d.executeArray(sql, args); err != nil {
+			return fmt.Errorf("failed to delete rows from %s: %v", table.Name, err)
+		}
+	}
+	return nil
+}
+
+// CompressPayloadRow gzips a single uncompressed payload in place.
+// It returns the stored size before and after compression.
+func (d *Database) CompressPayloadRow(table PayloadTable, id int64) (int64, int64, error) {
+	selectSQL := fmt.Sprintf("SELECT %s FROM %s WHERE id = ?", table.DataColumn, table.Name)
+	result, err := d.selectArray(selectSQL, []interface{}{id})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to read %s row %d: %v", table.Name, id, err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return 0, 0, nil
+	}
+
+	stored := result.GetStringValue_(0, 0)
+	if strings.HasPrefix(stored, compressedPayloadPrefix) {
+		return int64(len(stored)), int64(len(stored)), nil
+	}
+
+	compressed, err := compressPayload([]byte(stored))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Tiny payloads can grow when compressed; leave those as they are
+	if len(compressed) >= len(stored) {
+		return int64(len(stored)), int64(len(stored)), nil
+	}
+
+	updateSQL := fmt.Sprintf("UPDATE %s SET %s = ? WHERE id = ?", table.Name, table.DataColumn)
+	if err := d.executeArray(updateSQL, []interface{}{compressed, id}); err != nil {
+		return 0, 0, fmt.Errorf("failed to compress %s row %d: %v", table.Name, id, err)
+	}
+
+	return int64(len(stored)), int64(len(compressed)), nil
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ChannelSnapshot is a channel's statistics as recorded on one day
+type ChannelSnapshot struct {
+	ChannelID    string    `json:"channelId"`
+	SnapshotDate time.Time `json:"snapshotDate"`
+	Subscribers  int64     `json:"subscriberCount"`
+	ViewCount    int64     `json:"viewCount"`
+	VideoCount   int64     `json:"videoCount"`
+}
+
+// VideoSnapshot is a video's statistics as recorded on one day
+type VideoSnapshot struct {
+	VideoID      string    `json:"videoId"`
+	ChannelID    string    `json:"channelId"`
+	SnapshotDate time.Time `json:"snapshotDate"`
+	Views        int64     `json:"views"`
+	Likes        int64     `json:"likes"`
+	Comments     int64     `json:"comments"`
+}
+
+// RecordChannelSnapshot stores today's statistics for a channel, replacing any earlier snapshot from today
+func (d *Database) RecordChannelSnapshot(channel *Channel) error {
+	sql := `INSERT INTO channel_snapshots (channel_id, snapshot_date, subscriber_count, view_count, video_count)
+			VALUES (?, date('now'), ?, ?, ?)
+			ON CONFLICT(channel_id, snapshot_date) DO UPDATE SET
+				subscriber_count = excluded.subscriber_count,
+				view_count = excluded.view_count,
+				video_count = excluded.video_count,
+				captured_at = CURRENT_TIMESTAMP`
+
+	args := []interface{}{channel.ID, channel.Subscribers, channel.ViewCount, channel.VideoCount}
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to record channel snapshot: %v", err)
+	}
+	return nil
+}
+
+// RecordVideoSnapshots stores today's statistics for a channel's videos
+func (d *Database) RecordVideoSnapshots(channelID string, videos []Video) error {
+	for i := 0; i < len(videos); i += videoUpsertBatchSize {
+		end := i + videoUpsertBatchSize
+		if end > len(videos) {
+			end = len(videos)
+		}
+		batch := videos[i:end]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*5)
+		for _, v := range batch {
+			placeholders = append(placeholders, "(?, ?, date('now'), ?, ?, ?)")
+			args = append(args, v.ID, channelID, v.Views, v.Likes, v.Comments)
+		}
+
+		sql := `INSERT INTO video_snapshots (video_id, channel_id, snapshot_date, view_count, like_count, comment_count)
+				VALUES ` + strings.Join(placeholders, ", ") + `
+				ON CONFLICT(video_id, snapshot_date) DO UPDATE SET
+					view_count = excluded.view_count,
+					like_count = excluded.like_count,
+					comment_count = excluded.comment_count,
+					captured_at = CURRENT_TIMESTAMP`
+
+		if err := d.executeArray(sql, args); err != nil {
+			return fmt.Errorf("failed to record video snapshots: %v", err)
+		}
+	}
+	return nil
+}
+
+// GetChannelSnapshots returns a channel's daily snapshots, oldest first
+func (d *Database) GetChannelSnapshots(channelID string) ([]ChannelSnapshot, error) {
+	sql := `SELECT channel_id, snapshot_date, subscriber_count, view_count, video_count
+			FROM channel_snapshots
+			WHERE channel_id = ?
+			ORDER BY snapshot_date`
+
+	result, err := d.selectArray(sql, []interface{}{channelID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel snapshots: %v", err)
+	}
+
+	snapshots := make([]ChannelSnapshot, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		date, err := time.Parse("2006-01-02", result.GetStringValue_(r, 1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot_date: %v", err)
+		}
+		snapshots = append(snapshots, ChannelSnapshot{
+			ChannelID:    result.GetStringValue_(r, 0),
+			SnapshotDate: date,
+			Subscribers:  result.GetInt64Value_(r, 2),
+			ViewCount:    result.GetInt64Value_(r, 3),
+			VideoCount:   result.GetInt64Value_(r, 4),
+		})
+	}
+	return snapshots, nil
+}
+
+// GetVideoSnapshots returns the snapshots the age curve needs of every stored video of
+// a channel, oldest first: each video's first and latest snapshot, plus the two either
+// side of earlyDays of age, so views at that age can be interpolated. Snapshots are
+// placed at midday of their date, as the analytics package does.
+func (d *Database) GetVideoSnapshots(channelID string, earlyDays int) ([]VideoSnapshot, error) {
+	sql := `WITH aged AS (
+				SELECT s.video_id, s.channel_id, s.snapshot_date, s.view_count, s.like_count, s.comment_count,
+					julianday(s.snapshot_date) + 0.5 - julianday(v.published_at) AS age
+				FROM video_snapshots s
+				JOIN videos v ON v.id = s.video_id
+				WHERE s.channel_id = ?
+			), ranked AS (
+				SELECT *,
+					ROW_NUMBER() OVER (PARTITION BY video_id ORDER BY snapshot_date) AS from_first,
+					ROW_NUMBER() OVER (PARTITION BY video_id ORDER BY snapshot_date DESC) AS from_last,
+					ROW_NUMBER() OVER (PARTITION BY video_id, age <= ? ORDER BY snapshot_date DESC) AS before_early,
+					ROW_NUMBER() OVER (PARTITION BY video_id, age >= ? ORDER BY snapshot_date) AS after_early
+				FROM aged
+			)
+			SELECT video_id, channel_id, snapshot_date, view_count, like_count, comment_count
+			FROM ranked
+			WHERE from_first = 1 OR from_last = 1
+				OR (age <= ? AND before_early = 1)
+				OR (age >= ? AND after_early = 1)
+			ORDER BY video_id, snapshot_date`
+
+	result, err := d.selectArray(sql, []interface{}{channelID, earlyDays, earlyDays, earlyDays, earlyDays})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video snapshots: %v", err)
+	}
+
+	snapshots := make([]VideoSnapshot, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		date, err := time.Parse("2006-01-02", result.GetStringValue_(r, 2))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot_date: %v", err)
+		}
+		snapshots = append(snapshots, VideoSnapshot{
+			VideoID:      result.GetStringValue_(r, 0),
+			ChannelID:    result.GetStringValue_(r, 1),
+			SnapshotDate: date,
+			Views:        result.GetInt64Value_(r, 3),
+			Likes:        result.GetInt64Value_(r, 4),
+			Comments:     result.GetInt64Value_(r, 5),
+		})
+	}
+	return snapshots, nil
+}
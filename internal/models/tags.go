@@ -0,0 +1,84 @@
+package models
+
+import "time"
+
+// TagKind says where a tag came from
+type TagKind string
+
+const (
+	// TagKindTag is a tag set on the video by its uploader
+	TagKindTag TagKind = "tag"
+	// TagKindHashtag is a #hashtag found in the title or description
+	TagKindHashtag TagKind = "hashtag"
+)
+
+// TagStat is how often a tag or hashtag is used and how the videos using it performed
+type TagStat struct {
+	Tag         string  `json:"tag"`
+	Kind        TagKind `json:"kind"`
+	Videos      int     `json:"videos"`
+	MedianViews float64 `json:"medianViews"`
+	// MedianMultiple is the median of the videos' views relative to their outlier baseline
+	MedianMultiple float64 `json:"medianMultiple"`
+	// AboveBaselineShare is the fraction of the videos that beat their baseline
+	AboveBaselineShare float64   `json:"aboveBaselineShare"`
+	FirstUsed          time.Time `json:"firstUsed"`
+	LastUsed           time.Time `json:"lastUsed"`
+}
+
+// TagUsage is how many uploads used a tag in each month
+type TagUsage struct {
+	Tag     string            `json:"tag"`
+	Monthly []UploadFrequency `json:"monthly"`
+}
+
+// ChannelTags reports a channel's tags and hashtags
+type ChannelTags struct {
+	ChannelID    string `json:"channelId"`
+	ChannelTitle string `json:"channelTitle"`
+	TotalVideos  int    `json:"totalVideos"`
+	// TaggedVideos counts the videos with at least one tag or hashtag
+	TaggedVideos int `json:"taggedVideos"`
+	// MinVideos is the fewest videos a tag needs to be ranked by performance
+	MinVideos     int       `json:"minVideos"`
+	ByFrequency   []TagStat `json:"byFrequency"`
+	ByPerformance []TagStat `json:"byPerformance"`
+	// AboveBaseline are the tags whose videos mostly beat their baselines
+	AboveBaseline []TagStat `json:"aboveBaseline"`
+	// UsageOverTime covers the most frequent tags month by month, without gaps
+	UsageOverTime []TagUsage `json:"usageOverTime"`
+	Timestamp     time.Time  `json:"timestamp"`
+	CacheMetadata
+}
+
+// SharedTag is a tag used by more than one watchlisted channel
+type SharedTag struct {
+	Tag        string   `json:"tag"`
+	Kind       TagKind  `json:"kind"`
+	ChannelIDs []string `json:"channelIds"`
+	Videos     int      `json:"videos"`
+}
+
+// TagOverlap measures how much two channels' tags overlap
+type TagOverlap struct {
+	ChannelA   string `json:"channelA"`
+	ChannelB   string `json:"channelB"`
+	SharedTags int    `json:"sharedTags"`
+	// Jaccard is the shared tags divided by all the tags either channel uses
+	Jaccard float64 `json:"jaccard"`
+}
+
+// TagChannel is a channel taking part in a tag comparison
+type TagChannel struct {
+	ChannelID    string `json:"channelId"`
+	ChannelTitle string `json:"channelTitle"`
+	Tags         int    `json:"tags"`
+}
+
+// TagComparison finds the topics several channels share through their tags
+type TagComparison struct {
+	Channels   []TagChannel `json:"channels"`
+	SharedTags []SharedTag  `json:"sharedTags"`
+	Overlaps   []TagOverlap `json:"overlaps"`
+	Timestamp  time.Time    `json:"timestamp"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// TitleTerm is a word or phrase used in video titles and how those videos performed
+type TitleTerm struct {
+	Term string `json:"term"`
+	// Videos counts the titles containing the term, each at most once
+	Videos      int     `json:"videos"`
+	MedianViews float64 `json:"medianViews"`
+	// MedianMultiple is the median of the videos' views relative to their outlier baseline
+	MedianMultiple float64 `json:"medianMultiple"`
+}
+
+// TitleBinaryFeature compares videos whose titles have a feature with those that do not
+type TitleBinaryFeature struct {
+	Feature            string  `json:"feature"`
+	VideosWith         int     `json:"videosWith"`
+	MedianViewsWith    float64 `json:"medianViewsWith"`
+	MedianViewsWithout float64 `json:"medianViewsWithout"`
+	// Correlation is the rank correlation between having the feature and views
+	Correlation float64 `json:"correlation"`
+}
+
+// TitleNumericFeature relates a measured property of titles to views
+type TitleNumericFeature struct {
+	Feature string  `json:"feature"`
+	Mean    float64 `json:"mean"`
+	Median  float64 `json:"median"`
+	// Correlation is the rank correlation between the feature and views
+	Correlation float64 `json:"correlation"`
+}
+
+// ChannelTitles analyzes the words and structure of a channel's video titles
+type ChannelTitles struct {
+	ChannelID    string `json:"channelId"`
+	ChannelTitle string `json:"channelTitle"`
+	TotalVideos  int    `json:"totalVideos"`
+	// MinVideos is the fewest titles a term must appear in to be listed
+	MinVideos int `json:"minVideos"`
+	// Unigrams, Bigrams and Trigrams are ordered by Videos, then by MedianMultiple
+	Unigrams        []TitleTerm           `json:"unigrams"`
+	Bigrams         []TitleTerm           `json:"bigrams"`
+	Trigrams        []TitleTerm           `json:"trigrams"`
+	BinaryFeatures  []TitleBinaryFeature  `json:"binaryFeatures"`
+	NumericFeatures []TitleNumericFeature `json:"numericFeatures"`
+	Timestamp       time.Time             `json:"timestamp"`
+	CacheMetadata
+}
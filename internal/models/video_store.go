@@ -0,0 +1,215 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	sqlitecloud "github.com/sqlitecloud/sqlitecloud-go"
+)
+
+// videoUpsertBatchSize keeps each multi-row insert within SQLite's parameter limits
+const videoUpsertBatchSize = 50
+
+// UpsertVideos inserts or refreshes the given videos for a channel
+func (d *Database) UpsertVideos(channelID string, videos []Video) error {
+	return upsertVideos(d.executeArray, channelID, videos)
+}
+
+// SyncVideos replaces a channel's stored videos with the result of a complete crawl:
+// the videos are upserted, stored videos missing from the crawl are deleted along with
+// their snapshots and the channel is marked synced, all in one transaction
+func (d *Database) SyncVideos(channelID string, videos []Video) error {
+	return d.transaction(func(exec func(string, []interface{}) error, query func(string, []interface{}) (*sqlitecloud.Result, error)) error {
+		if err := upsertVideos(exec, channelID, videos); err != nil {
+			return err
+		}
+
+		result, err := query(`SELECT id FROM videos WHERE channel_id = ?`, []interface{}{channelID})
+		if err != nil {
+			return fmt.Errorf("failed to list videos of channel %s: %v", channelID, err)
+		}
+		crawled := make(map[string]bool, len(videos))
+		for _, v := range videos {
+			crawled[v.ID] = true
+		}
+		var removed []interface{}
+		for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+			if id := result.GetStringValue_(r, 0); !crawled[id] {
+				removed = append(removed, id)
+			}
+		}
+
+		for i := 0; i < len(removed); i += videoUpsertBatchSize {
+			end := i + videoUpsertBatchSize
+			if end > len(removed) {
+				end = len(removed)
+			}
+			batch := removed[i:end]
+			placeholders := `(?` + strings.Repeat(", ?", len(batch)-1) + `)`
+			if err := exec(`DELETE FROM video_snapshots WHERE video_id IN `+placeholders, batch); err != nil {
+				return fmt.Errorf("failed to delete snapshots of removed videos of channel %s: %v", channelID, err)
+			}
+			if err := exec(`DELETE FROM videos WHERE id IN `+placeholders, batch); err != nil {
+				return fmt.Errorf("failed to delete removed videos of channel %s: %v", channelID, err)
+			}
+		}
+
+		if err := exec(`UPDATE channels SET videos_synced_at = CURRENT_TIMESTAMP WHERE id = ?`, []interface{}{channelID}); err != nil {
+			return fmt.Errorf("failed to mark videos of channel %s synced: %v", channelID, err)
+		}
+		return nil
+	})
+}
+
+// GetVideoChannelID returns the channel a stored video belongs to,
+// or an empty string if it is not stored
+func (d *Database) GetVideoChannelID(videoID string) (string, error) {
+	result, err := d.selectArray(`SELECT channel_id FROM videos WHERE id = ?`, []interface{}{videoID})
+	if err != nil {
+		return "", fmt.Errorf("failed to get video %s: %v", videoID, err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return "", nil
+	}
+	return result.GetStringValue_(0, 0), nil
+}
+
+// DeleteVideo removes a stored video and its snapshots and returns the channel it
+// belonged to, or an empty string if it was not stored
+func (d *Database) DeleteVideo(videoID string) (string, error) {
+	channelID, err := d.GetVideoChannelID(videoID)
+	if err != nil || channelID == "" {
+		return "", err
+	}
+
+	err = d.transaction(func(exec func(string, []interface{}) error, query func(string, []interface{}) (*sqlitecloud.Result, error)) error {
+		if err := exec(`DELETE FROM video_snapshots WHERE video_id = ?`, []interface{}{videoID}); err != nil {
+			return fmt.Errorf("failed to delete snapshots of video %s: %v", videoID, err)
+		}
+		if err := exec(`DELETE FROM videos WHERE id = ?`, []interface{}{videoID}); err != nil {
+			return fmt.Errorf("failed to delete video %s: %v", videoID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return channelID, nil
+}
+
+// upsertVideos stores videos for a channel in batches through exec
+func upsertVideos(exec func(string, []interface{}) error, channelID string, videos []Video) error {
+	for i := 0; i < len(videos); i += videoUpsertBatchSize {
+		end := i + videoUpsertBatchSize
+		if end > len(videos) {
+			end = len(videos)
+		}
+		batch := videos[i:end]
+
+		placeholders := make([]string, 0, len(batch))
+		args := make([]interface{}, 0, len(batch)*11)
+		for _, v := range batch {
+			tags := v.Tags
+			if tags == nil {
+				tags = []string{}
+			}
+			tagsJSON, err := json.Marshal(tags)
+			if err != nil {
+				return fmt.Errorf("failed to encode tags of video %s: %v", v.ID, err)
+			}
+
+			placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)")
+			args = append(args,
+				v.ID,
+				channelID,
+				v.Title,
+				v.Description,
+				v.PublishedAt.UTC().Format(timestampLayout),
+				v.Duration,
+				v.Thumbnail,
+				string(tagsJSON),
+				v.Views,
+				v.Likes,
+				v.Comments,
+			)
+		}
+
+		sql := `INSERT INTO videos
+				(id, channel_id, title, description, published_at, duration, thumbnail_url,
+				 tags, view_count, like_count, comment_count, fetched_at)
+				VALUES ` + strings.Join(placeholders, ", ") + `
+				ON CONFLICT(id) DO UPDATE SET
+					channel_id = excluded.channel_id,
+					title = excluded.title,
+					description = excluded.description,
+					published_at = excluded.published_at,
+					duration = excluded.duration,
+					thumbnail_url = excluded.thumbnail_url,
+					tags = excluded.tags,
+					view_count = excluded.view_count,
+					like_count = excluded.like_count,
+					comment_count = excluded.comment_count,
+					fetched_at = CURRENT_TIMESTAMP`
+
+		if err := exec(sql, args); err != nil {
+			return fmt.Errorf("failed to upsert videos for channel %s: %v", channelID, err)
+		}
+	}
+	return nil
+}
+
+// GetVideosByChannel retrieves all stored videos for a channel, newest first
+func (d *Database) GetVideosByChannel(channelID string) ([]Video, error) {
+	sql := `SELECT id, channel_id, title, description, published_at, duration, thumbnail_url,
+			view_count, like_count, comment_count, tags
+			FROM videos
+			WHERE channel_id = ?
+			ORDER BY published_at DESC`
+
+	result, err := d.selectArray(sql, []interface{}{channelID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get videos: %v", err)
+	}
+
+	rowCount := result.GetNumberOfRows()
+	videos := make([]Video, 0, rowCount)
+	for r := uint64(0); r < rowCount; r++ {
+		publishedAt, err := time.Parse(timestampLayout, result.GetStringValue_(r, 4))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse published_at: %v", err)
+		}
+
+		var tags []string
+		if stored := result.GetStringValue_(r, 10); stored != "" {
+			if err := json.Unmarshal([]byte(stored), &tags); err != nil {
+				return nil, fmt.Errorf("failed to parse tags: %v", err)
+			}
+		}
+
+		views := result.GetInt64Value_(r, 7)
+		likes := result.GetInt64Value_(r, 8)
+		comments := result.GetInt64Value_(r, 9)
+
+		videos = append(videos, Video{
+			ID:           result.GetStringValue_(r, 0),
+			ChannelID:    result.GetStringValue_(r, 1),
+			Title:        result.GetStringValue_(r, 2),
+			Description:  result.GetStringValue_(r, 3),
+			Views:        views,
+			Likes:        likes,
+			Comments:     comments,
+			UploadDate:   publishedAt,
+			PublishedAt:  publishedAt,
+			Duration:     result.GetStringValue_(r, 5),
+			Thumbnail:    result.GetStringValue_(r, 6),
+			Tags:         tags,
+			ViewCount:    views,
+			LikeCount:    likes,
+			CommentCount: comments,
+		})
+	}
+
+	return videos, nil
+}
@@ -0,0 +1,135 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// WatchlistEntry is a channel the team tracks
+type WatchlistEntry struct {
+	ChannelID    string    `json:"channelId"`
+	ChannelTitle string    `json:"channelTitle"`
+	SourceURL    string    `json:"sourceUrl,omitempty"`
+	Labels       []string  `json:"labels"`
+	Notes        string    `json:"notes"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// WatchlistRequest is the body accepted by POST /watchlist
+type WatchlistRequest struct {
+	// Channel is a channel ID, an @handle or any channel URL form
+	Channel string   `json:"channel" binding:"required"`
+	Labels  []string `json:"labels"`
+	Notes   string   `json:"notes"`
+}
+
+// UpsertWatchlistEntry adds a channel to the watchlist or updates its labels and notes
+func (d *Database) UpsertWatchlistEntry(entry *WatchlistEntry) error {
+	labels := entry.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	labelsJSON, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+
+	sql := `INSERT INTO watchlist (channel_id, channel_title, source_url, labels, notes)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(channel_id) DO UPDATE SET
+				channel_title = excluded.channel_title,
+				source_url = excluded.source_url,
+				labels = excluded.labels,
+				notes = excluded.notes,
+				updated_at = CURRENT_TIMESTAMP`
+
+	args := []interface{}{entry.ChannelID, entry.ChannelTitle, entry.SourceURL, string(labelsJSON), entry.Notes}
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to store watchlist entry: %v", err)
+	}
+	return nil
+}
+
+// GetWatchlist returns every tracked channel, oldest first
+func (d *Database) GetWatchlist() ([]*WatchlistEntry, error) {
+	sql := `SELECT channel_id, channel_title, source_url, labels, notes, created_at, updated_at
+			FROM watchlist ORDER BY created_at, channel_id`
+
+	result, err := d.selectArray(sql, []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchlist: %v", err)
+	}
+
+	entries := make([]*WatchlistEntry, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		entry, err := parseWatchlistRow(result.GetStringValue_, r)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// GetWatchlistEntry returns a tracked channel, or nil if it is not on the watchlist
+func (d *Database) GetWatchlistEntry(channelID string) (*WatchlistEntry, error) {
+	sql := `SELECT channel_id, channel_title, source_url, labels, notes, created_at, updated_at
+			FROM watchlist WHERE channel_id = ?`
+
+	result, err := d.selectArray(sql, []interface{}{channelID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get watchlist entry: %v", err)
+	}
+
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+	return parseWatchlistRow(result.GetStringValue_, 0)
+}
+
+// DeleteWatchlistEntry removes a channel from the watchlist.
+// It reports whether the channel was being tracked.
+func (d *Database) DeleteWatchlistEntry(channelID string) (bool, error) {
+	existing, err := d.GetWatchlistEntry(channelID)
+	if err != nil {
+		return false, err
+	}
+	if existing == nil {
+		return false, nil
+	}
+
+	if err := d.executeArray(`DELETE FROM watchlist WHERE channel_id = ?`, []interface{}{channelID}); err != nil {
+		return false, fmt.Errorf("failed to delete watchlist entry: %v", err)
+	}
+	return true, nil
+}
+
+// parseWatchlistRow builds an entry from row r of a watchlist query
+func parseWatchlistRow(value func(row, column uint64) string, r uint64) (*WatchlistEntry, error) {
+	var labels []string
+	if err := json.Unmarshal([]byte(value(r, 3)), &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse labels: %v", err)
+	}
+
+	createdAt, err := time.Parse(timestampLayout, value(r, 5))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %v", err)
+	}
+
+	updatedAt, err := time.Parse(timestampLayout, value(r, 6))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %v", err)
+	}
+
+	return &WatchlistEntry{
+		ChannelID:    value(r, 0),
+		ChannelTitle: value(r, 1),
+		SourceURL:    value(r, 2),
+		Labels:       labels,
+		Notes:        value(r, 4),
+		CreatedAt:    createdAt,
+		UpdatedAt:    updatedAt,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// WebSubStatus is the state of a hub subscription
+type WebSubStatus string
+
+const (
+	// WebSubStatusPending means a request was sent and the hub has not verified it yet
+	WebSubStatusPending WebSubStatus = "pending"
+	// WebSubStatusActive means the hub verified the subscription and is delivering notifications
+	WebSubStatusActive WebSubStatus = "active"
+	// WebSubStatusUnsubscribing means an unsubscribe request awaits verification
+	WebSubStatusUnsubscribing WebSubStatus = "unsubscribing"
+	// WebSubStatusDenied means the hub refused the subscription
+	WebSubStatusDenied WebSubStatus = "denied"
+)
+
+// WebSubSubscription is a channel's push notification subscription with a WebSub hub
+type WebSubSubscription struct {
+	ChannelID          string       `json:"channelId"`
+	Topic              string       `json:"topic"`
+	Status             WebSubStatus `json:"status"`
+	LeaseSeconds       int64        `json:"leaseSeconds"`
+	ExpiresAt          *time.Time   `json:"expiresAt,omitempty"`
+	LastNotificationAt *time.Time   `json:"lastNotificationAt,omitempty"`
+	CreatedAt          time.Time    `json:"createdAt"`
+	UpdatedAt          time.Time    `json:"updatedAt"`
+}
+
+// websubColumns is the column list parseWebSubRow expects
+const websubColumns = `channel_id, topic, status, lease_seconds, expires_at, last_notification_at, created_at, updated_at`
+
+// SetWebSubRequested records that a subscribe or unsubscribe request was sent to the hub
+func (d *Database) SetWebSubRequested(channelID, topic string, status WebSubStatus) error {
+	sql := `INSERT INTO websub_subscriptions (channel_id, topic, status)
+			VALUES (?, ?, ?)
+			ON CONFLICT(channel_id) DO UPDATE SET
+				topic = excluded.topic,
+				status = excluded.status,
+				updated_at = CURRENT_TIMESTAMP`
+
+	if err := d.executeArray(sql, []interface{}{channelID, topic, string(status)}); err != nil {
+		return fmt.Errorf("failed to store websub subscription: %v", err)
+	}
+	return nil
+}
+
+// ActivateWebSubSubscription records a verified subscription and when its lease runs out
+func (d *Database) ActivateWebSubSubscription(topic string, leaseSeconds int64) error {
+	sql := `UPDATE websub_subscriptions
+			SET status = ?, lease_seconds = ?, expires_at = datetime('now', ?), updated_at = CURRENT_TIMESTAMP
+			WHERE topic = ?`
+
+	args := []interface{}{string(WebSubStatusActive), leaseSeconds, fmt.Sprintf("+%d seconds", leaseSeconds), topic}
+	if err := d.executeArray(sql, args); err != nil {
+		return fmt.Errorf("failed to activate websub subscription: %v", err)
+	}
+	return nil
+}
+
+// SetWebSubStatus updates the status of the subscription to a topic
+func (d *Database) SetWebSubStatus(topic string, status WebSubStatus) error {
+	sql := `UPDATE websub_subscriptions SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE topic = ?`
+
+	if err := d.executeArray(sql, []interface{}{string(status), topic}); err != nil {
+		return fmt.Errorf("failed to update websub subscription: %v", err)
+	}
+	return nil
+}
+
+// TouchWebSubSubscription records that a notification arrived for a channel
+func (d *Database) TouchWebSubSubscription(channelID string) error {
+	sql := `UPDATE websub_subscriptions SET last_notification_at = CURRENT_TIMESTAMP WHERE channel_id = ?`
+
+	if err := d.executeArray(sql, []interface{}{channelID}); err != nil {
+		return fmt.Errorf("failed to update websub subscription: %v", err)
+	}
+	return nil
+}
+
+// DeleteWebSubSubscription removes the subscription to a topic
+func (d *Database) DeleteWebSubSubscription(topic string) error {
+	sql := `DELETE FROM websub_subscriptions WHERE topic = ?`
+
+	if err := d.executeArray(sql, []interface{}{topic}); err != nil {
+		return fmt.Errorf("failed to delete websub subscription: %v", err)
+	}
+	return nil
+}
+
+// GetWebSubSubscriptionByTopic returns the subscription to a topic, or nil if there is none
+func (d *Database) GetWebSubSubscriptionByTopic(topic string) (*WebSubSubscription, error) {
+	sql := `SELECT ` + websubColumns + ` FROM websub_subscriptions WHERE topic = ?`
+
+	result, err := d.selectArray(sql, []interface{}{topic})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get websub subscription: %v", err)
+	}
+	if result.GetNumberOfRows() == 0 {
+		return nil, nil
+	}
+	return parseWebSubRow(result.GetStringValue_, result.GetInt64Value_, 0)
+}
+
+// GetWebSubSubscriptions returns every subscription, ordered by channel
+func (d *Database) GetWebSubSubscriptions() ([]*WebSubSubscription, error) {
+	sql := `SELECT ` + websubColumns + ` FROM websub_subscriptions ORDER BY channel_id`
+
+	result, err := d.selectArray(sql, []interface{}{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get websub subscriptions: %v", err)
+	}
+
+	subs := make([]*WebSubSubscription, 0, result.GetNumberOfRows())
+	for r := uint64(0); r < result.GetNumberOfRows(); r++ {
+		sub, err := parseWebSubRow(result.GetStringValue_, result.GetInt64Value_, r)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
+
+func parseWebSubRow(str func(row, column uint64) string, num func(row, column uint64) int64, r uint64) (*WebSubSubscription, error) {
+	createdAt, err := time.Parse(timestampLayout, str(r, 6))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at: %v", err)
+	}
+	updatedAt, err := time.Parse(timestampLayout, str(r, 7))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at: %v", err)
+	}
+
+	sub := &WebSubSubscription{
+		ChannelID:    str(r, 0),
+		Topic:        str(r, 1),
+		Status:       WebSubStatus(str(r, 2)),
+		LeaseSeconds: num(r, 3),
+		CreatedAt:    createdAt,
+		UpdatedAt:    updatedAt,
+	}
+	if value := str(r, 4); value != "" {
+		if expiresAt, err := time.Parse(timestampLayout, value); err == nil {
+			sub.ExpiresAt = &expiresAt
+		}
+	}
+	if value := str(r, 5); value != "" {
+		if notifiedAt, err := time.Parse(timestampLayout, value); err == nil {
+			sub.LastNotificationAt = &notifiedAt
+		}
+	}
+	return sub, nil
+}
@@ -0,0 +1,194 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// ErrRunInProgress is returned when a run is requested while another is still going
+var ErrRunInProgress = errors.New("a compaction is already running")
+
+// TableStats summarizes what a compaction run did to one table
+type TableStats struct {
+	Table                   string `json:"table"`
+	RowsScanned             int    `json:"rowsScanned"`
+	RowsDeleted             int    `json:"rowsDeleted"`
+	RowsCompressed          int    `json:"rowsCompressed"`
+	BytesDeleted            int64  `json:"bytesDeleted"`
+	BytesSavedByCompression int64  `json:"bytesSavedByCompression"`
+}
+
+// Stats summarizes a whole compaction run
+type Stats struct {
+	StartedAt      time.Time    `json:"startedAt"`
+	FinishedAt     time.Time    `json:"finishedAt"`
+	Tables         []TableStats `json:"tables"`
+	ReclaimedBytes int64        `json:"reclaimedBytes"`
+	// ExpiredCacheEntries is how many expired rows of the persistent cache were deleted
+	ExpiredCacheEntries int64  `json:"expiredCacheEntries"`
+	Error               string `json:"error,omitempty"`
+}
+
+// Compactor enforces a retention policy on the payload and snapshot tables
+type Compactor struct {
+	db       *models.Database
+	policy   Policy
+	compress bool
+	interval time.Duration
+
+	mu        sync.Mutex
+	running   bool
+	lastStats *Stats
+}
+
+// NewCompactor creates a compactor; compress also gzips payloads that survive the policy
+func NewCompactor(db *models.Database, policy Policy, compress bool, interval time.Duration) *Compactor {
+	return &Compactor{
+		db:       db,
+		policy:   policy,
+		compress: compress,
+		interval: interval,
+	}
+}
+
+// Start runs the compactor immediately and then on every interval until ctx is done.
+// A zero interval disables background compaction.
+func (c *Compactor) Start(ctx context.Context) {
+	if c.interval <= 0 {
+		log.Printf("Background compaction disabled")
+		return
+	}
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := c.RunOnce(); errors.Is(err, ErrRunInProgress) {
+			log.Printf("Skipping compaction: %v", err)
+		} else if err != nil {
+			log.Printf("Compaction failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce applies the retention policy to every payload and snapshot table and purges
+// expired cache entries. It returns ErrRunInProgress if a run is already going.
+func (c *Compactor) RunOnce() (*Stats, error) {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return nil, ErrRunInProgress
+	}
+	c.running = true
+	c.mu.Unlock()
+
+	stats := &Stats{StartedAt: time.Now()}
+	var runErr error
+	tables := append(append([]models.PayloadTable{}, models.PayloadTables...), models.SnapshotTables...)
+	for _, table := range tables {
+		tableStats, err := c.compactTable(table, stats.StartedAt)
+		stats.Tables = append(stats.Tables, tableStats)
+		stats.ReclaimedBytes += tableStats.BytesDeleted + tableStats.BytesSavedByCompression
+		if err != nil {
+			runErr = err
+			stats.Error = err.Error()
+			break
+		}
+	}
+	if runErr == nil {
+		// The cache only skips expired entries on read, so they are purged here
+		expired, err := c.db.DeleteExpiredCacheEntries(stats.StartedAt)
+		stats.ExpiredCacheEntries = expired
+		if err != nil {
+			runErr = err
+			stats.Error = err.Error()
+		}
+	}
+	stats.FinishedAt = time.Now()
+
+	log.Printf("Compaction finished in %v, reclaimed %d bytes", stats.FinishedAt.Sub(stats.StartedAt), stats.ReclaimedBytes)
+
+	c.mu.Lock()
+	c.running = false
+	c.lastStats = stats
+	c.mu.Unlock()
+
+	return stats, runErr
+}
+
+// LastStats returns the stats of the most recent run, or nil if none has completed
+func (c *Compactor) LastStats() *Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastStats
+}
+
+// compactTable drops rows outside the policy and compresses the rest if enabled
+func (c *Compactor) compactTable(table models.PayloadTable, now time.Time) (TableStats, error) {
+	stats := TableStats{Table: table.Name}
+
+	rows, err := c.db.ListPayloadRows(table)
+	if err != nil {
+		return stats, err
+	}
+	stats.RowsScanned = len(rows)
+
+	_, drop := c.policy.Select(rows, now)
+	dropped := make(map[int64]bool, len(drop))
+	for _, id := range drop {
+		dropped[id] = true
+	}
+
+	if len(drop) > 0 {
+		for _, row := range rows {
+			if dropped[row.ID] {
+				stats.BytesDeleted += row.Size
+			}
+		}
+
+		if err := c.db.DeletePayloadRows(table, drop); err != nil {
+			return stats, err
+		}
+		stats.RowsDeleted = len(drop)
+		log.Printf("Deleted %d rows from %s", len(drop), table.Name)
+	}
+
+	if !c.compress || table.DataColumn == "" {
+		return stats, nil
+	}
+
+	for _, row := range rows {
+		if row.Compressed || dropped[row.ID] {
+			continue
+		}
+		before, after, err := c.db.CompressPayloadRow(table, row.ID)
+		if err != nil {
+			return stats, err
+		}
+		if after < before {
+			stats.RowsCompressed++
+			stats.BytesSavedByCompression += before - after
+		}
+	}
+
+	return stats, nil
+}
+
+// sortNewestFirst orders payload rows by creation time, newest first
+func sortNewestFirst(rows []models.PayloadRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].CreatedAt.After(rows[j].CreatedAt)
+	})
+}
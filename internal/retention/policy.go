@@ -0,0 +1,75 @@
+package retention
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// Policy describes how long stored payloads are kept at each granularity.
+// Rows younger than DailyFor keep one version per day, rows younger than
+// WeeklyFor keep one per ISO week, and older rows keep one per month until
+// MonthlyFor. A zero MonthlyFor keeps monthlies forever.
+type Policy struct {
+	DailyFor   time.Duration
+	WeeklyFor  time.Duration
+	MonthlyFor time.Duration
+}
+
+// NewPolicy builds a policy from day counts
+func NewPolicy(dailyDays, weeklyDays, monthlyDays int) Policy {
+	day := 24 * time.Hour
+	return Policy{
+		DailyFor:   time.Duration(dailyDays) * day,
+		WeeklyFor:  time.Duration(weeklyDays) * day,
+		MonthlyFor: time.Duration(monthlyDays) * day,
+	}
+}
+
+// Select splits rows into the IDs to keep and the IDs to drop.
+// Within each group and bucket the newest row wins.
+func (p Policy) Select(rows []models.PayloadRow, now time.Time) (keep, drop []int64) {
+	seen := make(map[string]bool)
+
+	// Visit newest rows first so the first row seen in a bucket is the one kept
+	ordered := make([]models.PayloadRow, len(rows))
+	copy(ordered, rows)
+	sortNewestFirst(ordered)
+
+	for _, row := range ordered {
+		bucket, ok := p.bucket(row.CreatedAt, now)
+		if !ok {
+			drop = append(drop, row.ID)
+			continue
+		}
+
+		key := row.GroupKey + "|" + bucket
+		if seen[key] {
+			drop = append(drop, row.ID)
+			continue
+		}
+		seen[key] = true
+		keep = append(keep, row.ID)
+	}
+
+	return keep, drop
+}
+
+// bucket returns the retention bucket a row falls in, or false if it has expired
+func (p Policy) bucket(createdAt, now time.Time) (string, bool) {
+	age := now.Sub(createdAt)
+	t := createdAt.UTC()
+
+	switch {
+	case age < p.DailyFor:
+		return "d:" + t.Format("2006-01-02"), true
+	case age < p.WeeklyFor:
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("w:%d-W%02d", year, week), true
+	case p.MonthlyFor == 0 || age < p.MonthlyFor:
+		return "m:" + t.Format("2006-01"), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,243 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is a parsed five-field cron expression: minute, hour, day of month, month and day of week
+type Schedule struct {
+	expr     string
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// cronField describes the range of values one cron field accepts and, for months
+// and days of week, the names that may be used in place of numbers from min up
+type cronField struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 6, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// cronDescriptors are the shorthand expressions accepted in place of five fields
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// maxScheduleSearch bounds how far ahead Next looks for a matching time
+const maxScheduleSearch = 5 * 366 * 24 * time.Hour
+
+// ParseSchedule parses a standard cron expression such as "0 5 * * *".
+// Each field accepts *, single values, ranges (1-5), steps (*/15, 0-30/10) and
+// comma separated lists. Months and days of week may also be written as their
+// three-letter English names (jan, mon-fri), and day of week 7 is accepted as Sunday.
+func ParseSchedule(expr string, location *time.Location) (*Schedule, error) {
+	if location == nil {
+		location = time.UTC
+	}
+
+	spec := strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = descriptor
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid schedule %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	// Sunday may be written as 7
+	parts[4] = normalizeSunday(parts[4])
+
+	bits := make([]uint64, len(cronFields))
+	for i, field := range cronFields {
+		b, err := parseCronField(parts[i], field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %v", expr, err)
+		}
+		bits[i] = b
+	}
+
+	return &Schedule{
+		expr:     expr,
+		minute:   bits[0],
+		hour:     bits[1],
+		dom:      bits[2],
+		month:    bits[3],
+		dow:      bits[4],
+		domStar:  parts[2] == "*" || strings.HasPrefix(parts[2], "*/"),
+		dowStar:  parts[4] == "*" || strings.HasPrefix(parts[4], "*/"),
+		location: location,
+	}, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first time strictly after the given time that matches the schedule.
+// It returns the zero time if nothing matches within the search horizon, e.g. "0 0 31 2 *".
+// Local times skipped when the clocks go forward never match, and times repeated when
+// they go back match only the first time round.
+func (s *Schedule) Next(after time.Time) time.Time {
+	t := after.In(s.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxScheduleSearch)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = startOfHour(t.Year(), t.Month()+1, 1, 0, s.location)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = startOfHour(t.Year(), t.Month(), t.Day()+1, 0, s.location)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = startOfHour(t.Year(), t.Month(), t.Day(), t.Hour()+1, s.location)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 || repeatedWallClock(t) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// startOfHour returns the first instant of the given local hour. Where the clocks
+// going forward skip its start, time.Date resolves it to the hour before, so the
+// hour instead starts at the change.
+func startOfHour(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, hour, 0, 0, 0, loc)
+	want := time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+	if t.Day() != want.Day() || t.Hour() != want.Hour() || t.Minute() != 0 {
+		_, t = t.ZoneBounds()
+	}
+	return t
+}
+
+// repeatedWallClock reports whether t is the second occurrence of its local time, in
+// the hour repeated when the clocks go back. time.Date resolves such a local time to
+// its first occurrence.
+func repeatedWallClock(t time.Time) bool {
+	year, month, day := t.Date()
+	hour, minute, _ := t.Clock()
+	return !time.Date(year, month, day, hour, minute, 0, 0, t.Location()).Equal(t)
+}
+
+// dayMatches applies cron's rule that a restricted day of month and day of week
+// match when either one does
+func (s *Schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// parseCronField turns one comma separated field into a bit set of allowed values
+func parseCronField(value string, field cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(value, ",") {
+		if part == "" {
+			return 0, fmt.Errorf("empty %s value", field.name)
+		}
+
+		rangePart, step := part, 1
+		if idx := strings.Index(part, "/"); idx >= 0 {
+			rangePart = part[:idx]
+			n, err := strconv.Atoi(part[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("invalid %s step %q", field.name, part[idx+1:])
+			}
+			step = n
+		}
+
+		start, end := field.min, field.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			lo, err := parseCronValue(bounds[0], field)
+			if err != nil {
+				return 0, err
+			}
+			hi, err := parseCronValue(bounds[1], field)
+			if err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid %s range %q", field.name, rangePart)
+			}
+			start, end = lo, hi
+		default:
+			n, err := parseCronValue(rangePart, field)
+			if err != nil {
+				return 0, err
+			}
+			start = n
+			if step == 1 {
+				end = n
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(value string, field cronField) (int, error) {
+	for i, name := range field.names {
+		if strings.EqualFold(value, name) {
+			return field.min + i, nil
+		}
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < field.min || n > field.max {
+		return 0, fmt.Errorf("invalid %s %q (must be %d-%d)", field.name, value, field.min, field.max)
+	}
+	return n, nil
+}
+
+// normalizeSunday rewrites a day of week value of 7 to 0
+func normalizeSunday(value string) string {
+	parts := strings.Split(value, ",")
+	for i, part := range parts {
+		switch lower := strings.ToLower(part); {
+		case part == "7":
+			parts[i] = "0"
+		case strings.HasSuffix(part, "-7") || strings.HasSuffix(lower, "-sun"):
+			// A range ending on Sunday covers the start of the week through Saturday plus Sunday
+			parts[i] = part[:strings.LastIndex(part, "-")] + "-6,0"
+		}
+	}
+	return strings.Join(parts, ",")
+}
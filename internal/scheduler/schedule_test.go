@@ -0,0 +1,228 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// bitsOf returns the bit set of a field allowing the given values
+func bitsOf(values ...int) uint64 {
+	var bits uint64
+	for _, v := range values {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+// rangeBits returns the bit set of every value from lo to hi
+func rangeBits(lo, hi int) uint64 {
+	var bits uint64
+	for v := lo; v <= hi; v++ {
+		bits |= 1 << uint(v)
+	}
+	return bits
+}
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		expr                          string
+		minute, hour, dom, month, dow uint64
+		domStar, dowStar              bool
+	}{
+		{"0 5 * * *", bitsOf(0), bitsOf(5), rangeBits(1, 31), rangeBits(1, 12), rangeBits(0, 6), true, true},
+		{"*/15 */6 * * *", bitsOf(0, 15, 30, 45), bitsOf(0, 6, 12, 18), rangeBits(1, 31), rangeBits(1, 12), rangeBits(0, 6), true, true},
+		{"0-30/10 9-17 * * *", bitsOf(0, 10, 20, 30), rangeBits(9, 17), rangeBits(1, 31), rangeBits(1, 12), rangeBits(0, 6), true, true},
+		{"10/20 0 * * *", bitsOf(10, 30, 50), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), rangeBits(0, 6), true, true},
+		{"5,10-12,50 0 1,15 * *", bitsOf(5, 10, 11, 12, 50), bitsOf(0), bitsOf(1, 15), rangeBits(1, 12), rangeBits(0, 6), false, true},
+		{"0 0 * JAN,mar-May *", bitsOf(0), bitsOf(0), rangeBits(1, 31), bitsOf(1, 3, 4, 5), rangeBits(0, 6), true, true},
+		{"0 0 * * mon-FRI", bitsOf(0), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), rangeBits(1, 5), true, false},
+		{"0 0 * * 7", bitsOf(0), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), bitsOf(0), true, false},
+		{"0 0 * * 5-7", bitsOf(0), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), bitsOf(5, 6, 0), true, false},
+		{"0 0 * * fri-sun", bitsOf(0), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), bitsOf(5, 6, 0), true, false},
+		{"0 0 */2 * */2", bitsOf(0), bitsOf(0), bitsOf(1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31), rangeBits(1, 12), bitsOf(0, 2, 4, 6), true, true},
+		{"@daily", bitsOf(0), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), rangeBits(0, 6), true, true},
+		{"@Weekly", bitsOf(0), bitsOf(0), rangeBits(1, 31), rangeBits(1, 12), bitsOf(0), true, false},
+		{"  @hourly  ", bitsOf(0), rangeBits(0, 23), rangeBits(1, 31), rangeBits(1, 12), rangeBits(0, 6), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr, nil)
+			if err != nil {
+				t.Fatalf("ParseSchedule: %v", err)
+			}
+			if s.minute != tt.minute || s.hour != tt.hour || s.dom != tt.dom || s.month != tt.month || s.dow != tt.dow {
+				t.Errorf("fields = %b %b %b %b %b, want %b %b %b %b %b",
+					s.minute, s.hour, s.dom, s.month, s.dow, tt.minute, tt.hour, tt.dom, tt.month, tt.dow)
+			}
+			if s.domStar != tt.domStar || s.dowStar != tt.dowStar {
+				t.Errorf("domStar, dowStar = %v, %v, want %v, %v", s.domStar, s.dowStar, tt.domStar, tt.dowStar)
+			}
+			if s.location != time.UTC || s.String() != tt.expr {
+				t.Errorf("location, expr = %v, %q", s.location, s.String())
+			}
+		})
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@fortnightly",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"5-1 * * * *",
+		"1-2-3 * * * *",
+		"1,,2 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"* * * * monday",
+		"* * * mon *",
+		"* * * * jan",
+	} {
+		if _, err := ParseSchedule(expr, time.UTC); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	utc := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+	ny := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, newYork)
+	}
+	// Sao Paulo skipped midnight when its clocks went forward on 2018-11-04
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	// The second 01:30 on 2024-11-03, once New York is back on standard time
+	secondHalfPastOne := time.Date(2024, 11, 3, 6, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		expr  string
+		loc   *time.Location
+		after time.Time
+		want  []time.Time
+	}{
+		{
+			name:  "strictly after",
+			expr:  "0 5 * * *",
+			loc:   time.UTC,
+			after: utc(2024, 1, 1, 4, 59),
+			want:  []time.Time{utc(2024, 1, 1, 5, 0), utc(2024, 1, 2, 5, 0)},
+		},
+		{
+			name:  "seconds are truncated",
+			expr:  "*/15 * * * *",
+			loc:   time.UTC,
+			after: utc(2024, 1, 1, 0, 14).Add(59 * time.Second),
+			want:  []time.Time{utc(2024, 1, 1, 0, 15), utc(2024, 1, 1, 0, 30)},
+		},
+		{
+			name:  "restricted day of month or day of week",
+			expr:  "0 0 10 * fri",
+			loc:   time.UTC,
+			after: utc(2024, 9, 1, 0, 0),
+			want:  []time.Time{utc(2024, 9, 6, 0, 0), utc(2024, 9, 10, 0, 0), utc(2024, 9, 13, 0, 0), utc(2024, 9, 20, 0, 0)},
+		},
+		{
+			name:  "day of month alone",
+			expr:  "0 0 10 * *",
+			loc:   time.UTC,
+			after: utc(2024, 9, 1, 0, 0),
+			want:  []time.Time{utc(2024, 9, 10, 0, 0), utc(2024, 10, 10, 0, 0)},
+		},
+		{
+			name:  "stepped day of week still requires the day of month",
+			expr:  "0 0 1-7 * */7",
+			loc:   time.UTC,
+			after: utc(2024, 9, 1, 0, 0),
+			want:  []time.Time{utc(2024, 10, 6, 0, 0), utc(2024, 11, 3, 0, 0)},
+		},
+		{
+			name:  "leap day",
+			expr:  "0 0 29 feb *",
+			loc:   time.UTC,
+			after: utc(2024, 3, 1, 0, 0),
+			want:  []time.Time{utc(2028, 2, 29, 0, 0)},
+		},
+		{
+			name:  "never",
+			expr:  "0 0 31 2 *",
+			loc:   time.UTC,
+			after: utc(2024, 1, 1, 0, 0),
+			want:  []time.Time{{}},
+		},
+		{
+			name:  "same local time across spring forward",
+			expr:  "0 5 * * *",
+			loc:   newYork,
+			after: ny(2024, 3, 9, 5, 0),
+			want:  []time.Time{ny(2024, 3, 10, 5, 0), ny(2024, 3, 11, 5, 0)},
+		},
+		{
+			name:  "time skipped by spring forward",
+			expr:  "30 2 * * *",
+			loc:   newYork,
+			after: ny(2024, 3, 9, 3, 0),
+			want:  []time.Time{ny(2024, 3, 11, 2, 30)},
+		},
+		{
+			name:  "day whose midnight is skipped",
+			expr:  "0 12 * * *",
+			loc:   saoPaulo,
+			after: time.Date(2018, 11, 3, 12, 0, 0, 0, saoPaulo),
+			want:  []time.Time{time.Date(2018, 11, 4, 12, 0, 0, 0, saoPaulo), time.Date(2018, 11, 5, 12, 0, 0, 0, saoPaulo)},
+		},
+		{
+			name:  "time repeated by fall back runs once",
+			expr:  "30 1 * * *",
+			loc:   newYork,
+			after: ny(2024, 11, 2, 12, 0),
+			want:  []time.Time{ny(2024, 11, 3, 1, 30), ny(2024, 11, 4, 1, 30)},
+		},
+		{
+			name:  "hourly through fall back",
+			expr:  "0 * * * *",
+			loc:   newYork,
+			after: ny(2024, 11, 3, 0, 30),
+			want:  []time.Time{ny(2024, 11, 3, 1, 0), secondHalfPastOne.Add(30 * time.Minute), ny(2024, 11, 3, 3, 0)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.expr, tt.loc)
+			if err != nil {
+				t.Fatalf("ParseSchedule: %v", err)
+			}
+			after := tt.after
+			for i, want := range tt.want {
+				got := s.Next(after)
+				if !got.Equal(want) {
+					t.Fatalf("run %d after %v = %v, want %v", i, after, got, want)
+				}
+				if !got.IsZero() && got.Location() != tt.loc {
+					t.Errorf("run %d is in %v, want %v", i, got.Location(), tt.loc)
+				}
+				after = got
+			}
+		})
+	}
+}
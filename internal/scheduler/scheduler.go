@@ -0,0 +1,297 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// ErrRunInProgress is returned when a run is requested while another is still going
+var ErrRunInProgress = errors.New("a scheduled refresh is already running")
+
+// errQuotaExhausted marks channels skipped because the day's quota budget is spent
+var errQuotaExhausted = errors.New("daily quota budget exhausted")
+
+const (
+	jobScheduledRefresh = "scheduled_refresh"
+	jobRefreshChannel   = "refresh_channel"
+)
+
+// Refresher refreshes the stored analytics, trends and snapshots of one channel
+type Refresher interface {
+	RefreshChannel(channelID string) error
+}
+
+// RunSummary summarizes one pass over the watchlist
+type RunSummary struct {
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Channels   int        `json:"channels"`
+	Refreshed  int        `json:"refreshed"`
+	Failed     int        `json:"failed"`
+	Skipped    int        `json:"skipped"`
+	QuotaUnits int64      `json:"quotaUnits"`
+}
+
+// Status describes the scheduler for the jobs endpoint
+type Status struct {
+	Enabled        bool        `json:"enabled"`
+	Schedule       string      `json:"schedule"`
+	Spread         string      `json:"spread"`
+	Running        bool        `json:"running"`
+	NextRun        *time.Time  `json:"nextRun,omitempty"`
+	DailyQuota     int64       `json:"dailyQuota"`
+	QuotaUsedToday int64       `json:"quotaUsedToday"`
+	LastRun        *RunSummary `json:"lastRun,omitempty"`
+}
+
+// Scheduler periodically refreshes every channel on the watchlist.
+// Channels are staggered evenly across the spread window and a daily budget of
+// API quota units caps how much work a day's runs may do.
+type Scheduler struct {
+	db         *models.Database
+	refresher  Refresher
+	schedule   *Schedule
+	spread     time.Duration
+	dailyQuota int64
+
+	mu        sync.Mutex
+	enabled   bool
+	running   bool
+	nextRun   time.Time
+	lastRun   *RunSummary
+	quotaDay  string
+	quotaUsed int64
+}
+
+// New creates a scheduler; a zero dailyQuota disables the quota budget
+func New(db *models.Database, refresher Refresher, schedule *Schedule, spread time.Duration, dailyQuota int64) *Scheduler {
+	return &Scheduler{
+		db:         db,
+		refresher:  refresher,
+		schedule:   schedule,
+		spread:     spread,
+		dailyQuota: dailyQuota,
+	}
+}
+
+// Start waits for each scheduled time and runs a refresh until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	s.enabled = true
+	s.mu.Unlock()
+
+	log.Printf("Scheduler started with schedule %q", s.schedule)
+
+	for {
+		next := s.schedule.Next(time.Now())
+		if next.IsZero() {
+			log.Printf("Schedule %q never fires, scheduler stopped", s.schedule)
+			return
+		}
+
+		s.mu.Lock()
+		s.nextRun = next
+		s.mu.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := s.RunOnce(ctx); err != nil {
+			log.Printf("Scheduled refresh failed: %v", err)
+		}
+	}
+}
+
+// RunOnce refreshes every watchlisted channel, spreading the work across the window
+func (s *Scheduler) RunOnce(ctx context.Context) (*RunSummary, error) {
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrRunInProgress
+	}
+	s.running = true
+	summary := &RunSummary{StartedAt: time.Now().UTC()}
+	s.lastRun = summary
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	runID, err := s.db.StartJobRun(jobScheduledRefresh, "")
+	if err != nil {
+		log.Printf("Failed to record scheduled refresh: %v", err)
+	}
+
+	runErr := s.refreshWatchlist(ctx, summary)
+
+	s.mu.Lock()
+	finishedAt := time.Now().UTC()
+	summary.FinishedAt = &finishedAt
+	s.mu.Unlock()
+
+	status := models.JobRunStatusSucceeded
+	if runErr != nil || summary.Failed > 0 {
+		status = models.JobRunStatusFailed
+	}
+	if runErr == nil && summary.Failed > 0 {
+		runErr = fmt.Errorf("%d of %d channels failed to refresh", summary.Failed, summary.Channels)
+	}
+	if runID != 0 {
+		if err := s.db.FinishJobRun(runID, status, summary.QuotaUnits, runErr); err != nil {
+			log.Printf("Failed to record scheduled refresh result: %v", err)
+		}
+	}
+
+	log.Printf("Scheduled refresh finished: %d refreshed, %d failed, %d skipped, ~%d quota units",
+		summary.Refreshed, summary.Failed, summary.Skipped, summary.QuotaUnits)
+
+	return summary, runErr
+}
+
+// refreshWatchlist refreshes each watchlisted channel in turn
+func (s *Scheduler) refreshWatchlist(ctx context.Context, summary *RunSummary) error {
+	entries, err := s.db.GetWatchlist()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	summary.Channels = len(entries)
+	s.mu.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Stagger channels so the run's API calls are spread over the window instead of bursting
+	gap := s.spread / time.Duration(len(entries))
+
+	for i, entry := range entries {
+		if i > 0 && gap > 0 {
+			timer := time.NewTimer(gap)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		status, units, err := s.refreshChannel(entry.ChannelID)
+
+		s.mu.Lock()
+		switch status {
+		case models.JobRunStatusSucceeded:
+			summary.Refreshed++
+		case models.JobRunStatusSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+		summary.QuotaUnits += units
+		s.mu.Unlock()
+
+		if err != nil {
+			log.Printf("Scheduled refresh of channel %s %s: %v", entry.ChannelID, status, err)
+		}
+	}
+	return nil
+}
+
+// refreshChannel refreshes a single channel within the quota budget and records the job run
+func (s *Scheduler) refreshChannel(channelID string) (models.JobRunStatus, int64, error) {
+	runID, err := s.db.StartJobRun(jobRefreshChannel, channelID)
+	if err != nil {
+		log.Printf("Failed to record refresh of channel %s: %v", channelID, err)
+	}
+
+	status, units, runErr := models.JobRunStatusSucceeded, int64(0), error(nil)
+	estimate := s.estimateQuota(channelID)
+	if !s.reserveQuota(estimate) {
+		status, runErr = models.JobRunStatusSkipped, errQuotaExhausted
+	} else {
+		units = estimate
+		if runErr = s.refresher.RefreshChannel(channelID); runErr != nil {
+			status = models.JobRunStatusFailed
+		}
+	}
+
+	if runID != 0 {
+		if err := s.db.FinishJobRun(runID, status, units, runErr); err != nil {
+			log.Printf("Failed to record refresh result of channel %s: %v", channelID, err)
+		}
+	}
+	return status, units, runErr
+}
+
+// estimateQuota approximates the API units a full crawl of a channel costs:
+// one channels.list call plus a playlistItems.list and videos.list call per 50 uploads
+func (s *Scheduler) estimateQuota(channelID string) int64 {
+	channel, err := s.db.GetChannel(channelID)
+	if err != nil || channel == nil {
+		return 1
+	}
+	pages := (channel.VideoCount + 49) / 50
+	return 1 + 2*pages
+}
+
+// reserveQuota takes units from today's budget, returning false if they don't fit
+func (s *Scheduler) reserveQuota(units int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// YouTube resets quotas at midnight Pacific time; a UTC day is close enough for budgeting
+	today := time.Now().UTC().Format("2006-01-02")
+	if s.quotaDay != today {
+		s.quotaDay = today
+		s.quotaUsed = 0
+	}
+
+	if s.dailyQuota > 0 && s.quotaUsed+units > s.dailyQuota {
+		return false
+	}
+	s.quotaUsed += units
+	return true
+}
+
+// Status reports the scheduler's configuration and progress
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := Status{
+		Enabled:    s.enabled,
+		Schedule:   s.schedule.String(),
+		Spread:     s.spread.String(),
+		Running:    s.running,
+		DailyQuota: s.dailyQuota,
+	}
+	if s.enabled && !s.nextRun.IsZero() {
+		next := s.nextRun
+		status.NextRun = &next
+	}
+	if s.quotaDay == time.Now().UTC().Format("2006-01-02") {
+		status.QuotaUsedToday = s.quotaUsed
+	}
+	if s.lastRun != nil {
+		last := *s.lastRun
+		status.LastRun = &last
+	}
+	return status
+}
@@ -0,0 +1,62 @@
+package websub
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Feed is the Atom document a hub pushes when a channel uploads or updates a video
+type Feed struct {
+	XMLName xml.Name       `xml:"http://www.w3.org/2005/Atom feed"`
+	Links   []Link         `xml:"http://www.w3.org/2005/Atom link"`
+	Entries []Entry        `xml:"http://www.w3.org/2005/Atom entry"`
+	Deleted []DeletedEntry `xml:"http://purl.org/atompub/tombstones/1.0 deleted-entry"`
+}
+
+// Link is an Atom link element
+type Link struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+// Entry is one published or updated video
+type Entry struct {
+	ID        string    `xml:"http://www.w3.org/2005/Atom id"`
+	VideoID   string    `xml:"http://www.youtube.com/xml/schemas/2015 videoId"`
+	ChannelID string    `xml:"http://www.youtube.com/xml/schemas/2015 channelId"`
+	Title     string    `xml:"http://www.w3.org/2005/Atom title"`
+	Published time.Time `xml:"http://www.w3.org/2005/Atom published"`
+	Updated   time.Time `xml:"http://www.w3.org/2005/Atom updated"`
+}
+
+// DeletedEntry is a tombstone for a video that was removed or made private
+type DeletedEntry struct {
+	Ref  string `xml:"ref,attr"`
+	When string `xml:"when,attr"`
+}
+
+// VideoID returns the ID of the deleted video from its "yt:video:<id>" reference
+func (d DeletedEntry) VideoID() string {
+	return strings.TrimPrefix(d.Ref, "yt:video:")
+}
+
+// Topic returns the feed's self link, which is the topic it was published to
+func (f *Feed) Topic() string {
+	for _, link := range f.Links {
+		if link.Rel == "self" {
+			return link.Href
+		}
+	}
+	return ""
+}
+
+// ParseFeed decodes a pushed Atom notification
+func ParseFeed(body []byte) (*Feed, error) {
+	var feed Feed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("failed to parse atom feed: %v", err)
+	}
+	return &feed, nil
+}
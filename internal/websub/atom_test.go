@@ -0,0 +1,121 @@
+package websub
+
+import (
+	"testing"
+	"time"
+)
+
+// uploadFeed is the notification the fake hub publishes for an upload
+const uploadFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:yt="http://www.youtube.com/xml/schemas/2015" xmlns="http://www.w3.org/2005/Atom">
+  <link rel="hub" href="https://pubsubhubbub.appspot.com"/>
+  <link rel="self" href="https://www.youtube.com/xml/feeds/videos.xml?channel_id=UCabc123"/>
+  <title>YouTube video feed</title>
+  <updated>2024-03-01T12:00:00Z</updated>
+  <entry>
+    <id>yt:video:vid001</id>
+    <yt:videoId>vid001</yt:videoId>
+    <yt:channelId>UCabc123</yt:channelId>
+    <title>Sample upload &amp; more</title>
+    <link rel="alternate" href="https://www.youtube.com/watch?v=vid001"/>
+    <author>
+      <name>Fake Hub</name>
+      <uri>https://www.youtube.com/channel/UCabc123</uri>
+    </author>
+    <published>2024-03-01T11:30:00Z</published>
+    <updated>2024-03-01T12:00:00Z</updated>
+  </entry>
+</feed>
+`
+
+// deletedFeed is the notification the fake hub publishes for a removed video
+const deletedFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns:at="http://purl.org/atompub/tombstones/1.0" xmlns="http://www.w3.org/2005/Atom">
+  <at:deleted-entry ref="yt:video:vid001" when="2024-03-02T08:00:00Z">
+    <link href="https://www.youtube.com/watch?v=vid001"/>
+    <at:by>
+      <name>Fake Hub</name>
+      <uri>https://www.youtube.com/channel/UCabc123</uri>
+    </at:by>
+  </at:deleted-entry>
+</feed>
+`
+
+func TestParseFeedUpload(t *testing.T) {
+	feed, err := ParseFeed([]byte(uploadFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+
+	if got, want := feed.Topic(), TopicURL("UCabc123"); got != want {
+		t.Errorf("Topic = %q, want %q", got, want)
+	}
+	if got := ChannelIDFromTopic(feed.Topic()); got != "UCabc123" {
+		t.Errorf("ChannelIDFromTopic = %q, want UCabc123", got)
+	}
+	if len(feed.Deleted) != 0 {
+		t.Errorf("got %d deleted entries, want 0", len(feed.Deleted))
+	}
+	if len(feed.Entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(feed.Entries))
+	}
+
+	want := Entry{
+		ID:        "yt:video:vid001",
+		VideoID:   "vid001",
+		ChannelID: "UCabc123",
+		Title:     "Sample upload & more",
+		Published: time.Date(2024, 3, 1, 11, 30, 0, 0, time.UTC),
+		Updated:   time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+	got := feed.Entries[0]
+	if got.ID != want.ID || got.VideoID != want.VideoID || got.ChannelID != want.ChannelID || got.Title != want.Title {
+		t.Errorf("entry = %+v, want %+v", got, want)
+	}
+	if !got.Published.Equal(want.Published) || !got.Updated.Equal(want.Updated) {
+		t.Errorf("entry times = %v / %v, want %v / %v", got.Published, got.Updated, want.Published, want.Updated)
+	}
+}
+
+func TestParseFeedDeleted(t *testing.T) {
+	feed, err := ParseFeed([]byte(deletedFeed))
+	if err != nil {
+		t.Fatalf("ParseFeed: %v", err)
+	}
+
+	if len(feed.Entries) != 0 {
+		t.Errorf("got %d entries, want 0", len(feed.Entries))
+	}
+	if feed.Topic() != "" {
+		t.Errorf("Topic = %q, want none", feed.Topic())
+	}
+	if len(feed.Deleted) != 1 {
+		t.Fatalf("got %d deleted entries, want 1", len(feed.Deleted))
+	}
+	deleted := feed.Deleted[0]
+	if deleted.VideoID() != "vid001" {
+		t.Errorf("VideoID = %q, want vid001", deleted.VideoID())
+	}
+	if deleted.When != "2024-03-02T08:00:00Z" {
+		t.Errorf("When = %q, want 2024-03-02T08:00:00Z", deleted.When)
+	}
+}
+
+func TestParseFeedInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"empty", ""},
+		{"truncated", `<feed xmlns="http://www.w3.org/2005/Atom"><entry>`},
+		{"not xml", `{"videoId":"vid001"}`},
+		{"wrong root", `<rss><channel/></rss>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if feed, err := ParseFeed([]byte(tt.body)); err == nil {
+				t.Errorf("ParseFeed = %+v, want an error", feed)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package websub
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yt-insights/internal/models"
+)
+
+// topicBaseURL is the feed YouTube publishes a channel's uploads to
+const topicBaseURL = "https://www.youtube.com/xml/feeds/videos.xml?channel_id="
+
+// pendingRetryAfter is how long a subscription may wait for hub verification before it is re-sent
+const pendingRetryAfter = time.Hour
+
+// TopicURL returns the WebSub topic of a channel's upload feed
+func TopicURL(channelID string) string {
+	return topicBaseURL + url.QueryEscape(channelID)
+}
+
+// ChannelIDFromTopic extracts the channel ID from a topic URL
+func ChannelIDFromTopic(topic string) string {
+	u, err := url.Parse(topic)
+	if err != nil {
+		return ""
+	}
+	return u.Query().Get("channel_id")
+}
+
+// Sign returns the X-Hub-Signature value of a body under the given secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha1.New, []byte(secret))
+	mac.Write(body)
+	return "sha1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Subscriber keeps the watchlisted channels subscribed to a WebSub hub
+type Subscriber struct {
+	db            *models.Database
+	hubURL        string
+	callbackURL   string
+	secret        string
+	lease         time.Duration
+	renewInterval time.Duration
+	client        *http.Client
+}
+
+// NewSubscriber creates a subscriber that asks the hub to deliver notifications to callbackURL.
+// Notifications are signed with secret when it is not empty.
+func NewSubscriber(db *models.Database, hubURL, callbackURL, secret string, lease, renewInterval time.Duration) *Subscriber {
+	return &Subscriber{
+		db:            db,
+		hubURL:        hubURL,
+		callbackURL:   callbackURL,
+		secret:        secret,
+		lease:         lease,
+		renewInterval: renewInterval,
+		client:        &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Start syncs subscriptions with the watchlist immediately and then on every renew interval
+func (s *Subscriber) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(); err != nil {
+			log.Printf("WebSub sync failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync subscribes new watchlist channels, renews leases that would lapse before the next
+// sync and unsubscribes channels that are no longer tracked
+func (s *Subscriber) Sync() error {
+	entries, err := s.db.GetWatchlist()
+	if err != nil {
+		return err
+	}
+	subs, err := s.db.GetWebSubSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	existing := make(map[string]*models.WebSubSubscription, len(subs))
+	for _, sub := range subs {
+		existing[sub.ChannelID] = sub
+	}
+
+	// Renew with a full interval to spare so one failed attempt doesn't drop the lease
+	renewBy := time.Now().UTC().Add(2 * s.renewInterval)
+	tracked := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		tracked[entry.ChannelID] = true
+		if !s.needsSubscribe(existing[entry.ChannelID], renewBy) {
+			continue
+		}
+		if err := s.Subscribe(entry.ChannelID); err != nil {
+			log.Printf("Failed to subscribe channel %s: %v", entry.ChannelID, err)
+		}
+	}
+
+	for _, sub := range subs {
+		if tracked[sub.ChannelID] || sub.Status == models.WebSubStatusUnsubscribing {
+			continue
+		}
+		if err := s.Unsubscribe(sub.ChannelID); err != nil {
+			log.Printf("Failed to unsubscribe channel %s: %v", sub.ChannelID, err)
+		}
+	}
+	return nil
+}
+
+// needsSubscribe reports whether a channel's subscription must be (re)requested
+func (s *Subscriber) needsSubscribe(sub *models.WebSubSubscription, renewBy time.Time) bool {
+	if sub == nil {
+		return true
+	}
+	switch sub.Status {
+	case models.WebSubStatusActive:
+		return sub.ExpiresAt == nil || sub.ExpiresAt.Before(renewBy)
+	case models.WebSubStatusPending:
+		return time.Since(sub.UpdatedAt) > pendingRetryAfter
+	default:
+		return true
+	}
+}
+
+// Subscribe asks the hub to push a channel's uploads to the callback
+func (s *Subscriber) Subscribe(channelID string) error {
+	topic := TopicURL(channelID)
+	if err := s.db.SetWebSubRequested(channelID, topic, models.WebSubStatusPending); err != nil {
+		return err
+	}
+	return s.request("subscribe", topic)
+}
+
+// Unsubscribe asks the hub to stop pushing a channel's uploads
+func (s *Subscriber) Unsubscribe(channelID string) error {
+	topic := TopicURL(channelID)
+	if err := s.db.SetWebSubRequested(channelID, topic, models.WebSubStatusUnsubscribing); err != nil {
+		return err
+	}
+	return s.request("unsubscribe", topic)
+}
+
+// request sends a subscription change to the hub; verification happens asynchronously
+func (s *Subscriber) request(mode, topic string) error {
+	form := url.Values{
+		"hub.mode":     {mode},
+		"hub.topic":    {topic},
+		"hub.callback": {s.callbackURL},
+		"hub.verify":   {"async"},
+	}
+	if mode == "subscribe" {
+		form.Set("hub.lease_seconds", strconv.FormatInt(int64(s.lease/time.Second), 10))
+		if s.secret != "" {
+			form.Set("hub.secret", s.secret)
+		}
+	}
+
+	resp, err := s.client.PostForm(s.hubURL, form)
+	if err != nil {
+		return fmt.Errorf("failed to reach hub: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("hub rejected %s request: %s: %s", mode, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	log.Printf("Sent WebSub %s request for %s", mode, topic)
+	return nil
+}
+
+// Verify answers a hub's intent verification. It returns true if the request matches a
+// subscription change we asked for, in which case the challenge must be echoed back.
+func (s *Subscriber) Verify(mode, topic string, leaseSeconds int64) (bool, error) {
+	sub, err := s.db.GetWebSubSubscriptionByTopic(topic)
+	if err != nil {
+		return false, err
+	}
+	if sub == nil {
+		return false, nil
+	}
+
+	switch mode {
+	case "subscribe":
+		if sub.Status != models.WebSubStatusPending && sub.Status != models.WebSubStatusActive {
+			return false, nil
+		}
+		if leaseSeconds <= 0 {
+			leaseSeconds = int64(s.lease / time.Second)
+		}
+		return true, s.db.ActivateWebSubSubscription(topic, leaseSeconds)
+	case "unsubscribe":
+		if sub.Status != models.WebSubStatusUnsubscribing {
+			return false, nil
+		}
+		return true, s.db.DeleteWebSubSubscription(topic)
+	case "denied":
+		return true, s.db.SetWebSubStatus(topic, models.WebSubStatusDenied)
+	default:
+		return false, nil
+	}
+}
+
+// VerifySignature checks a notification's X-Hub-Signature header. Without a configured
+// secret every notification is accepted.
+func (s *Subscriber) VerifySignature(header string, body []byte) bool {
+	if s.secret == "" {
+		return true
+	}
+
+	algorithm, signature, ok := strings.Cut(header, "=")
+	if !ok {
+		return false
+	}
+
+	var newHash func() hash.Hash
+	switch algorithm {
+	case "sha1":
+		newHash = sha1.New
+	case "sha256":
+		newHash = sha256.New
+	case "sha384":
+		newHash = sha512.New384
+	case "sha512":
+		newHash = sha512.New
+	default:
+		return false
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(newHash, []byte(s.secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
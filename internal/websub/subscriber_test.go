@@ -0,0 +1,65 @@
+package websub
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"testing"
+	"time"
+)
+
+// signed returns an X-Hub-Signature header for body under the given algorithm
+func signed(algorithm string, newHash func() hash.Hash, secret string, body []byte) string {
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return algorithm + "=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(uploadFeed)
+	s := NewSubscriber(nil, "", "", "hubsecret", time.Hour, time.Hour)
+
+	tests := []struct {
+		name   string
+		header string
+		want   bool
+	}{
+		{"sha1", Sign("hubsecret", body), true},
+		{"sha256", signed("sha256", sha256.New, "hubsecret", body), true},
+		{"sha384", signed("sha384", sha512.New384, "hubsecret", body), true},
+		{"sha512", signed("sha512", sha512.New, "hubsecret", body), true},
+		{"wrong secret", Sign("othersecret", body), false},
+		{"wrong body", Sign("hubsecret", []byte(deletedFeed)), false},
+		{"algorithm mismatch", "sha1=" + signed("sha256", sha256.New, "hubsecret", body)[len("sha256="):], false},
+		{"unknown algorithm", signed("md5", sha256.New, "hubsecret", body), false},
+		{"missing separator", "sha1", false},
+		{"bad hex", "sha1=not-hex", false},
+		{"empty", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.VerifySignature(tt.header, body); got != tt.want {
+				t.Errorf("VerifySignature(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySignatureWithoutSecret(t *testing.T) {
+	s := NewSubscriber(nil, "", "", "", time.Hour, time.Hour)
+	for _, header := range []string{"", "sha1=deadbeef", "garbage"} {
+		if !s.VerifySignature(header, []byte(uploadFeed)) {
+			t.Errorf("VerifySignature(%q) = false without a secret, want true", header)
+		}
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("hubsecret", []byte("hello"))
+	want := "sha1=f6f8e2e57116e8feea91171922e54cceb149648f"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}